@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/automoli"
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/benleb/automoli-go/internal/style"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// pauseCmd represents the pause command.
+var pauseCmd = &cobra.Command{
+	Use:   "pause <room> <duration>",
+	Short: icons.Pause + " pause a room for a duration, e.g. \"bedroom 2h\" (a duration of 0 resumes the room)",
+	Args:  cobra.ExactArgs(2),
+
+	Run: func(_ *cobra.Command, args []string) {
+		setupLogging()
+
+		roomName, rawDuration := args[0], args[1]
+
+		duration, err := time.ParseDuration(rawDuration)
+		if err != nil {
+			models.Printer.With("err", err).Errorf("invalid duration %s", style.Bold(rawDuration))
+
+			os.Exit(1)
+		}
+
+		// events are not needed here but must be drained to keep the client running
+		events := make(chan *homeassistant.EventMsg)
+
+		go func() {
+			for range events { //nolint:revive
+			}
+		}()
+
+		hass, err := homeassistant.New(viper.GetString("homeassistant.url"), viper.GetString("homeassistant.token"), &events)
+		if err != nil {
+			models.Printer.With("err", err).Error("creating homeassistant client failed")
+
+			os.Exit(1)
+		}
+
+		// the running AutoMoLi instance listens for this event
+		if err := hass.FireEvent(homeassistant.EventAutoMoLiPause, map[string]interface{}{"room": roomName, "duration": duration.String()}); err != nil {
+			models.Printer.With("err", err).Errorf("sending pause request for %s failed", style.Bold(roomName))
+
+			os.Exit(1)
+		}
+
+		if duration > 0 {
+			models.Printer.Printf("%s %s %s paused for %s", automoli.AppIcon, icons.Pause, style.Bold(roomName), style.Bold(duration.String()))
+		} else {
+			models.Printer.Printf("%s %s %s resumed", automoli.AppIcon, icons.Play, style.Bold(roomName))
+		}
+	},
+}
+
+func init() { //nolint:gochecknoinits
+	rootCmd.AddCommand(pauseCmd)
+}
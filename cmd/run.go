@@ -25,35 +25,7 @@ var runCmd = &cobra.Command{
 		fmt.Println(lipgloss.NewStyle().Padding(2, 4).Render(automoli.ASCIIHeader))
 
 		// general log settings & style
-		lipgloss.SetColorProfile(termenv.TrueColor)
-		log.SetColorProfile(termenv.TrueColor)
-
-		var logLevel log.Level
-
-		// set log level
-		switch {
-		case viper.GetBool("automoli.debug"):
-			logLevel = log.DebugLevel
-
-		case viper.GetBool("automoli.verbose"):
-			logLevel = log.InfoLevel
-
-		default:
-			logLevel = log.WarnLevel
-		}
-
-		// report timestamps only when run in docker, otherwise we rely on systemd journald or similar
-		reportTimestamp := runningInDocker()
-
-		models.Printer = log.NewWithOptions(os.Stdout, log.Options{
-			ReportTimestamp: reportTimestamp,
-			TimeFormat:      " " + "15:04:05",
-			ReportCaller:    logLevel < log.InfoLevel,
-			Level:           logLevel,
-		})
-
-		// set color profile for loggers
-		models.Printer.SetColorProfile(termenv.TrueColor)
+		setupLogging()
 
 		// run automoli
 		if aml := automoli.New(); aml == nil {
@@ -91,6 +63,39 @@ func init() { //nolint:gochecknoinits
 	viper.SetDefault("homeassistant.defaults.watchdog_check_every", 7*time.Second)
 }
 
+// setupLogging sets the log level & style and creates the printer.
+func setupLogging() {
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	log.SetColorProfile(termenv.TrueColor)
+
+	var logLevel log.Level
+
+	// set log level
+	switch {
+	case viper.GetBool("automoli.debug"):
+		logLevel = log.DebugLevel
+
+	case viper.GetBool("automoli.verbose"):
+		logLevel = log.InfoLevel
+
+	default:
+		logLevel = log.WarnLevel
+	}
+
+	// report timestamps only when run in docker, otherwise we rely on systemd journald or similar
+	reportTimestamp := runningInDocker()
+
+	models.Printer = log.NewWithOptions(os.Stdout, log.Options{
+		ReportTimestamp: reportTimestamp,
+		TimeFormat:      " " + "15:04:05",
+		ReportCaller:    logLevel < log.InfoLevel,
+		Level:           logLevel,
+	})
+
+	// set color profile for loggers
+	models.Printer.SetColorProfile(termenv.TrueColor)
+}
+
 // // runningViaSystemd checks if the process is running via systemd.
 // func runningViaSystemd() bool {
 // 	return os.Getenv("INVOCATION_ID") != ""
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/benleb/automoli-go/internal/automoli"
+	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command.
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: icons.Checklist + " print the JSON Schema of the config file (for editor autocompletion & validation)",
+
+	Run: func(_ *cobra.Command, _ []string) {
+		setupLogging()
+
+		rawSchema, err := json.MarshalIndent(automoli.Schema(), "", "  ")
+		if err != nil {
+			models.Printer.With("err", err).Error("encoding schema failed")
+
+			os.Exit(1)
+		}
+
+		fmt.Println(string(rawSchema))
+	},
+}
+
+func init() { //nolint:gochecknoinits
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/automoli"
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/benleb/automoli-go/internal/style"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// validateCmd represents the validate command.
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: icons.Checklist + " validate the config file (exits non-zero on errors)",
+
+	Run: func(cmd *cobra.Command, _ []string) {
+		setupLogging()
+
+		var hass *homeassistant.HomeAssistant
+
+		if live, _ := cmd.Flags().GetBool("live"); live {
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			var err error
+			if hass, err = connectHomeAssistant(timeout); err != nil {
+				models.Printer.With("err", err).Error("connecting to home assistant failed")
+
+				os.Exit(1)
+			}
+		}
+
+		report := automoli.Validate(hass)
+
+		for _, warning := range report.Warnings {
+			models.Printer.Warn("⚠️  " + warning)
+		}
+
+		for _, validationErr := range report.Errors {
+			models.Printer.Error("❌ " + validationErr)
+		}
+
+		if !report.OK() {
+			models.Printer.Errorf("%s %s is invalid | %s errors, %s warnings", automoli.AppIcon, style.Bold(viper.ConfigFileUsed()), style.Bold(strconv.Itoa(len(report.Errors))), style.Bold(strconv.Itoa(len(report.Warnings))))
+
+			os.Exit(1)
+		}
+
+		models.Printer.Printf("%s %s %s is valid | %s warnings", automoli.AppIcon, icons.GreenTick.String(), style.Bold(viper.ConfigFileUsed()), style.Bold(strconv.Itoa(len(report.Warnings))))
+	},
+}
+
+// connectHomeAssistant creates a home assistant client - the client retries forever, so we give up after the timeout.
+func connectHomeAssistant(timeout time.Duration) (*homeassistant.HomeAssistant, error) {
+	// events are not needed here but must be drained to keep the client running
+	events := make(chan *homeassistant.EventMsg)
+
+	go func() {
+		for range events { //nolint:revive
+		}
+	}()
+
+	type result struct {
+		hass *homeassistant.HomeAssistant
+		err  error
+	}
+
+	connected := make(chan result, 1)
+
+	go func() {
+		hass, err := homeassistant.New(viper.GetString("homeassistant.url"), viper.GetString("homeassistant.token"), &events)
+		connected <- result{hass: hass, err: err}
+	}()
+
+	select {
+	case res := <-connected:
+		return res.hass, res.err
+
+	case <-time.After(timeout):
+		return nil, models.ErrConnectionTimeout
+	}
+}
+
+func init() { //nolint:gochecknoinits
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().Bool("live", false, "connect to home assistant and check that the referenced entities exist")
+	validateCmd.Flags().Duration("timeout", 30*time.Second, "time to wait for the connection to home assistant")
+}
@@ -0,0 +1,197 @@
+package automoli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/models/adaptive"
+	"github.com/benleb/automoli-go/internal/models/daytime"
+	"github.com/benleb/automoli-go/internal/models/domain"
+	"github.com/benleb/automoli-go/internal/style"
+	"golang.org/x/exp/slices"
+)
+
+// setsAdaptiveValues checks if the daytime sets a brightness or color temperature (replaced in the sun mode).
+func setsAdaptiveValues(dt *daytime.Daytime) bool {
+	return (dt.BrightnessPct != nil && *dt.BrightnessPct > 0) || dt.ColorTempKelvin != nil
+}
+
+// adaptiveValues calculates the adaptive color temperature & brightness for the given time.
+// returns false if adaptive lighting is disabled, the night mode is active or no values could be calculated.
+func (r *Room) adaptiveValues(now time.Time) (adaptive.Values, bool) {
+	if !r.Adaptive.IsEnabled() || r.nightModeActive.Load() {
+		return adaptive.Values{}, false
+	}
+
+	switch r.Adaptive.Mode {
+	case adaptive.Sun:
+		factor, err := r.sunFactor(now)
+		if err != nil {
+			r.pr.Debugf("%s no adaptive values: %+v", icons.Sun, err)
+
+			return adaptive.Values{}, false
+		}
+
+		return r.Adaptive.ForSunFactor(factor), true
+
+	case adaptive.Curve:
+		return r.curveValues(now)
+	}
+
+	return adaptive.Values{}, false
+}
+
+// sunFactor is the elevation of the sun relative to its highest elevation of the day (0 while the sun is below the horizon).
+func (r *Room) sunFactor(now time.Time) (float64, error) {
+	sunrise, sunset, err := r.aml.location.Times(now)
+	if err != nil {
+		return 0, err
+	}
+
+	elevation, err := r.aml.location.Elevation(now)
+	if err != nil {
+		return 0, err
+	}
+
+	noonElevation, err := r.aml.location.Elevation(sunrise.Add(sunset.Sub(sunrise) / 2))
+	if err != nil || noonElevation <= 0 {
+		return 0, err
+	}
+
+	return elevation / noonElevation, nil
+}
+
+// curveValues interpolates between the anchors of the active & the next daytime.
+// values only set on the active daytime are kept until the next daytime starts.
+func (r *Room) curveValues(now time.Time) (adaptive.Values, bool) {
+	activeDaytime := r.GetActiveDaytime()
+
+	todaysDaytimes := r.daytimesOn(now)
+	if len(todaysDaytimes) == 0 {
+		todaysDaytimes = r.sortedDaytimes()
+	}
+
+	// active daytime started yesterday if its start time (today) is still ahead
+	start := activeDaytime.Start.Time
+	if start.After(now) {
+		start = start.AddDate(0, 0, -1)
+	}
+
+	// the daytime following the active one (wraps around to the first daytime of the day)
+	nextDaytime := todaysDaytimes[(slices.Index(todaysDaytimes, activeDaytime)+1)%len(todaysDaytimes)]
+
+	nextStart := nextDaytime.Start.Time
+	for !nextStart.After(start) {
+		nextStart = nextStart.AddDate(0, 0, 1)
+	}
+
+	fraction := float64(now.Sub(start)) / float64(nextStart.Sub(start))
+
+	values := adaptive.Values{}
+
+	if from := activeDaytime.ColorTempKelvin; from != nil {
+		kelvin := *from
+		if to := nextDaytime.ColorTempKelvin; to != nil {
+			kelvin = uint16(adaptive.Interpolate(float64(*from), float64(*to), fraction) + 0.5)
+		}
+
+		values.ColorTempKelvin = &kelvin
+	}
+
+	if from := activeDaytime.BrightnessPct; from != nil && *from > 0 {
+		brightnessPct := *from
+		if to := nextDaytime.BrightnessPct; to != nil && *to > 0 {
+			brightnessPct = uint8(adaptive.Interpolate(float64(*from), float64(*to), fraction) + 0.5)
+		}
+
+		values.BrightnessPct = &brightnessPct
+	}
+
+	return values, values.ColorTempKelvin != nil || values.BrightnessPct != nil
+}
+
+// applyAdaptiveValues adds the adaptive color temperature & brightness to (a copy of) the service data.
+func (r *Room) applyAdaptiveValues(serviceData map[string]interface{}) map[string]interface{} {
+	values, ok := r.adaptiveValues(time.Now())
+	if !ok {
+		return serviceData
+	}
+
+	adaptiveServiceData := make(map[string]interface{}, len(serviceData)+2)
+	for key, value := range serviceData {
+		adaptiveServiceData[key] = value
+	}
+
+	if values.ColorTempKelvin != nil {
+		adaptiveServiceData["color_temp_kelvin"] = *values.ColorTempKelvin
+	}
+
+	if values.BrightnessPct != nil {
+		adaptiveServiceData["brightness_pct"] = *values.BrightnessPct
+	}
+
+	return adaptiveServiceData
+}
+
+// adaptiveNudger periodically adjusts the lights turned on by AutoMoLi to the current adaptive values.
+func (r *Room) adaptiveNudger() {
+	ticker := time.NewTicker(r.Adaptive.NudgeEvery)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		r.nudgeAdaptive()
+	}
+}
+
+// nudgeAdaptive adjusts the lights that are on to the current adaptive values.
+func (r *Room) nudgeAdaptive() {
+	r.Lock()
+	defer r.Unlock()
+
+	switch {
+	// only lights we turned on and that were not adjusted manually since
+	case !r.turnedOnByAutoMoLi || r.manualOverride:
+		return
+
+	// dimmed lights are about to be turned off, paused or disabled rooms are left alone
+	case r.dimmed || r.isPaused() || r.isDisabled():
+		return
+
+	// no scenes - they have their own light settings
+	case r.isDisabledByLightConfiguration() || slices.ContainsFunc(r.GetActiveDaytime().Targets, func(target homeassistant.EntityID) bool { return target.Domain() == domain.Scene }):
+		return
+	}
+
+	lightsOn := r.lightsOn()
+	if len(lightsOn) == 0 {
+		return
+	}
+
+	values, ok := r.adaptiveValues(time.Now())
+	if !ok {
+		return
+	}
+
+	serviceData := map[string]interface{}{"transition": r.GetActiveDaytime().Transition.Seconds()}
+
+	adjusted := make([]string, 0, 2)
+
+	if values.ColorTempKelvin != nil {
+		serviceData["color_temp_kelvin"] = *values.ColorTempKelvin
+
+		adjusted = append(adjusted, style.Bold(fmt.Sprint(*values.ColorTempKelvin))+"K")
+	}
+
+	if values.BrightnessPct != nil {
+		serviceData["brightness_pct"] = *values.BrightnessPct
+
+		adjusted = append(adjusted, style.Bold(fmt.Sprint(*values.BrightnessPct))+"%")
+	}
+
+	r.ha.TurnOn(lightsOn, serviceData)
+
+	r.pr.Infof("%s adaptive nudge %s %s", icons.Sun, style.DarkDivider.String(), strings.Join(adjusted, " "))
+}
@@ -0,0 +1,238 @@
+package automoli
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/metrics"
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/benleb/automoli-go/internal/style"
+)
+
+// apiReadHeaderTimeout limits the time to read the request headers.
+const apiReadHeaderTimeout = 5 * time.Second
+
+// apiRequest is the (optional) json body of the control endpoints.
+type apiRequest struct {
+	// Duration of a pause, e.g. "2h"
+	Duration string `json:"duration,omitempty"`
+	// Daytime to switch to
+	Daytime string `json:"daytime,omitempty"`
+}
+
+// startAPI starts the http server serving the room states & controls.
+func (aml *AutoMoLi) startAPI(listen string) {
+	if aml.HTTP.Token == "" && !isLoopback(listen) {
+		aml.Pr.Warnf("%s http api on %s accepts controls from anyone in the network | set automoli.http.token to require a token", icons.Key, style.Bold(listen))
+	}
+
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           aml.apiHandler(),
+		ReadHeaderTimeout: apiReadHeaderTimeout,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			aml.Pr.With("err", err).Errorf("❌ http server on %s failed", style.Bold(listen))
+		}
+	}()
+
+	aml.Pr.Infof("%s http api listening on %s", icons.Rocket, style.Bold(listen))
+}
+
+// apiHandler returns the routes of the http api.
+func (aml *AutoMoLi) apiHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	// room states
+	mux.HandleFunc("GET /api/rooms", aml.handleGetRooms)
+	mux.HandleFunc("GET /api/rooms/{room}", aml.withRoom(aml.handleGetRoom))
+
+	// room controls
+	mux.HandleFunc("POST /api/rooms/{room}/on", aml.withRoom(aml.handleOn))
+	mux.HandleFunc("POST /api/rooms/{room}/off", aml.withRoom(aml.handleOff))
+	mux.HandleFunc("POST /api/rooms/{room}/pause", aml.withRoom(aml.handlePause))
+	mux.HandleFunc("POST /api/rooms/{room}/resume", aml.withRoom(aml.handleResume))
+	mux.HandleFunc("POST /api/rooms/{room}/daytime", aml.withRoom(aml.handleDaytime))
+
+	// liveness & readiness
+	mux.HandleFunc("GET /healthz", aml.handleHealthz)
+	mux.HandleFunc("GET /readyz", aml.handleReadyz)
+
+	// prometheus metrics
+	mux.Handle("GET /metrics", metrics.Handler())
+
+	return mux
+}
+
+// withRoom looks up the room of the request path and passes it to the handler.
+// controls (POST requests) need the configured token as bearer token.
+func (aml *AutoMoLi) withRoom(handler func(http.ResponseWriter, *http.Request, *Room)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost && !aml.isAuthorized(req) {
+			writeError(w, models.ErrUnauthorized)
+
+			return
+		}
+
+		room, err := aml.room(req.PathValue("room"))
+		if err != nil {
+			writeError(w, err)
+
+			return
+		}
+
+		handler(w, req, room)
+	}
+}
+
+// isAuthorized checks the bearer token of the request - all requests are authorized if no token is configured.
+func (aml *AutoMoLi) isAuthorized(req *http.Request) bool {
+	if aml.HTTP == nil || aml.HTTP.Token == "" {
+		return true
+	}
+
+	token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+
+	return found && subtle.ConstantTimeCompare([]byte(token), []byte(aml.HTTP.Token)) == 1
+}
+
+// isLoopback checks if the listen address only accepts connections from the local machine.
+func isLoopback(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+func (aml *AutoMoLi) handleGetRooms(w http.ResponseWriter, _ *http.Request) {
+	states := make([]RoomStatus, 0, len(aml.rooms))
+
+	for _, room := range aml.rooms {
+		states = append(states, room.Status())
+	}
+
+	writeJSON(w, http.StatusOK, states)
+}
+
+func (aml *AutoMoLi) handleGetRoom(w http.ResponseWriter, _ *http.Request, room *Room) {
+	writeJSON(w, http.StatusOK, room.Status())
+}
+
+func (aml *AutoMoLi) handleOn(w http.ResponseWriter, _ *http.Request, room *Room) {
+	if err := room.ForceOn(); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, room.Status())
+}
+
+func (aml *AutoMoLi) handleOff(w http.ResponseWriter, _ *http.Request, room *Room) {
+	room.ForceOff()
+
+	writeJSON(w, http.StatusOK, room.Status())
+}
+
+func (aml *AutoMoLi) handlePause(w http.ResponseWriter, req *http.Request, room *Room) {
+	body, err := decodeAPIRequest(req)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	duration, err := time.ParseDuration(body.Duration)
+	if err != nil {
+		writeError(w, fmt.Errorf("invalid duration %q: %w", body.Duration, err))
+
+		return
+	}
+
+	room.Pause(duration)
+
+	writeJSON(w, http.StatusOK, room.Status())
+}
+
+func (aml *AutoMoLi) handleResume(w http.ResponseWriter, _ *http.Request, room *Room) {
+	room.Resume()
+
+	writeJSON(w, http.StatusOK, room.Status())
+}
+
+func (aml *AutoMoLi) handleDaytime(w http.ResponseWriter, req *http.Request, room *Room) {
+	body, err := decodeAPIRequest(req)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	if err := room.SetDaytime(body.Daytime); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, room.Status())
+}
+
+// decodeAPIRequest decodes the json body or falls back to the query parameters, e.g. ?duration=2h.
+func decodeAPIRequest(req *http.Request) (apiRequest, error) {
+	body := apiRequest{
+		Duration: req.URL.Query().Get("duration"),
+		Daytime:  req.URL.Query().Get("daytime"),
+	}
+
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return body, nil
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return body, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return body, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// writeError responds with the error and a matching status code.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+
+	switch {
+	case errors.Is(err, models.ErrUnknownRoom), errors.Is(err, models.ErrUnknownDaytime):
+		status = http.StatusNotFound
+
+	case errors.Is(err, models.ErrDaytimeDisabled), errors.Is(err, models.ErrNightModeActive):
+		status = http.StatusConflict
+
+	case errors.Is(err, models.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	}
+
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
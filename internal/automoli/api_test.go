@@ -0,0 +1,70 @@
+package automoli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestAPIToken checks that controls need the configured bearer token while the room states are public.
+func TestAPIToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		method        string
+		authorization string
+		want          int
+	}{
+		// the room is unknown - authorized requests get a 404
+		{"no token configured", "", http.MethodPost, "", http.StatusNotFound},
+		{"valid token", "secret", http.MethodPost, "Bearer secret", http.StatusNotFound},
+		{"missing token", "secret", http.MethodPost, "", http.StatusUnauthorized},
+		{"wrong token", "secret", http.MethodPost, "Bearer guess", http.StatusUnauthorized},
+		{"token without bearer prefix", "secret", http.MethodPost, "secret", http.StatusUnauthorized},
+		{"state without token", "secret", http.MethodGet, "", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			aml := &AutoMoLi{Config: &Config{HTTP: &HTTPConfig{Listen: "127.0.0.1:8337", Token: test.token}}}
+
+			path := "/api/rooms/attic"
+			if test.method == http.MethodPost {
+				path += "/off"
+			}
+
+			req := httptest.NewRequest(test.method, path, nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			aml.apiHandler().ServeHTTP(recorder, req)
+
+			if recorder.Code != test.want {
+				t.Errorf("status = %d, want %d | body: %s", recorder.Code, test.want, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		listen string
+		want   bool
+	}{
+		{"127.0.0.1:8337", true},
+		{"localhost:8337", true},
+		{"[::1]:8337", true},
+		{":8337", false},
+		{"0.0.0.0:8337", false},
+		{"192.168.1.10:8337", false},
+		{"invalid", false},
+	}
+
+	for _, test := range tests {
+		if got := isLoopback(test.listen); got != test.want {
+			t.Errorf("isLoopback(%q) = %t, want %t", test.listen, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package automoli
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/models/autodelay"
+	"github.com/benleb/automoli-go/internal/style"
+)
+
+const (
+	// motionGapsKept is the number of recent gaps between motion events kept per daytime.
+	motionGapsKept = 200
+	// autoDelayMinGaps is the number of gaps needed before the learned delay is used.
+	autoDelayMinGaps = 10
+	// autoDelayBumpHalfLife is the time after which half of a bump has decayed.
+	autoDelayBumpHalfLife = 24 * time.Hour
+)
+
+// delayBump increases the learned delay after the lights were turned back on right after an automatic turn off.
+type delayBump struct {
+	factor float64
+	at     time.Time
+}
+
+// factorAt returns the bump factor decayed towards 1 until the given time.
+func (b delayBump) factorAt(now time.Time) float64 {
+	return 1 + (b.factor-1)*math.Pow(0.5, float64(now.Sub(b.at))/float64(autoDelayBumpHalfLife))
+}
+
+// motionHistory remembers the gaps between motion events to learn the delay per daytime (delay: auto).
+type motionHistory struct {
+	settings *autodelay.Settings
+
+	// recent gaps between motion events per daytime
+	gaps map[string][]time.Duration
+	// bumps per daytime - increased if the lights are turned on manually right after they were turned off
+	bumps map[string]delayBump
+
+	mu sync.Mutex
+}
+
+func newMotionHistory(settings *autodelay.Settings) *motionHistory {
+	return &motionHistory{
+		settings: settings,
+		gaps:     make(map[string][]time.Duration),
+		bumps:    make(map[string]delayBump),
+	}
+}
+
+// record adds a gap between two motion events during the given daytime.
+func (mh *motionHistory) record(daytimeName string, gap time.Duration) {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+
+	gaps := append(mh.gaps[daytimeName], gap)
+	if len(gaps) > motionGapsKept {
+		gaps = gaps[len(gaps)-motionGapsKept:]
+	}
+
+	mh.gaps[daytimeName] = gaps
+}
+
+// observe records the gap between the last motion and the motion at motionAt.
+// if the lights were turned off automatically in between (turnedOffAt), the gap was longer than the delay and is only
+// recorded if the motion came within bump_within after the turn off - later motion is most likely someone new entering.
+// without these gaps, the learned delay would only ever shrink as only gaps shorter than the delay were known.
+func (mh *motionHistory) observe(daytimeName string, lastMotion, motionAt, turnedOffAt time.Time) {
+	if !turnedOffAt.IsZero() && motionAt.Sub(turnedOffAt) > mh.settings.BumpWithin {
+		return
+	}
+
+	mh.record(daytimeName, motionAt.Sub(lastMotion))
+}
+
+// delay returns the learned delay for the given daytime or the fallback if there are not enough gaps yet.
+func (mh *motionHistory) delay(daytimeName string, fallback time.Duration) time.Duration {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+
+	learned := fallback
+	if gaps := mh.gaps[daytimeName]; len(gaps) >= autoDelayMinGaps {
+		learned = autodelay.Percentile(gaps, mh.settings.Percentile)
+	}
+
+	if bump, ok := mh.bumps[daytimeName]; ok {
+		learned = time.Duration(float64(learned) * bump.factorAt(time.Now()))
+	}
+
+	return mh.settings.Clamp(learned)
+}
+
+// bump increases the delay of the given daytime and returns the new bump factor.
+// the bump decays over time - the delay returns to the learned one if the lights are not turned back on again.
+func (mh *motionHistory) bump(daytimeName string) float64 {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+
+	now := time.Now()
+
+	factor := 1.0
+	if bump, ok := mh.bumps[daytimeName]; ok {
+		factor = bump.factorAt(now)
+	}
+
+	mh.bumps[daytimeName] = delayBump{factor: factor * mh.settings.BumpFactor, at: now}
+
+	return mh.bumps[daytimeName].factor
+}
+
+// recordMotionGap learns the gap between the last motion and the motion at motionAt - while the lights are
+// on or right after they were turned off because no motion was detected.
+func (r *Room) recordMotionGap(motionAt time.Time) {
+	// the last motion happened while the lights were on or turned them on
+	lightsOnAtLastMotion := r.lastMotion.After(r.lastSwitchedOn) || r.lastMotion.Equal(r.turnOnMotion)
+
+	if r.motionHistory == nil || r.lastMotion.IsZero() || !lightsOnAtLastMotion {
+		return
+	}
+
+	var turnedOffAt time.Time
+
+	switch {
+	case r.turnedOffByTimer && r.lastSwitchedOff.After(r.lastMotion):
+		turnedOffAt = r.lastSwitchedOff
+
+	// the lights were turned off (and on again) since the last motion
+	case r.lastSwitchedOff.After(r.lastMotion):
+		return
+
+	case !r.isLightOn():
+		return
+	}
+
+	r.motionHistory.observe(r.GetActiveDaytime().Name, r.lastMotion, motionAt, turnedOffAt)
+}
+
+// bumpAutoDelay increases the learned delay of the active daytime if the lights were turned
+// on manually shortly after they were turned off because no motion was detected.
+func (r *Room) bumpAutoDelay() {
+	activeDaytime := r.GetActiveDaytime()
+
+	if r.motionHistory == nil || !activeDaytime.IsAutoDelay() || !r.turnedOffByTimer || time.Since(r.lastSwitchedOff) > r.AutoDelay.BumpWithin {
+		return
+	}
+
+	bump := r.motionHistory.bump(activeDaytime.Name)
+
+	r.pr.Printf("%s turned back on %s after turning off %s delay bumped to %s (x%.2f)", icons.Timer, style.Bold(time.Since(r.lastSwitchedOff).Round(time.Second).String()), style.DarkDivider.String(), style.Bold(r.GetActiveDelay().Round(time.Second).String()), bump)
+}
@@ -0,0 +1,119 @@
+package automoli
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/models/autodelay"
+)
+
+// TestMotionHistoryDelayStable simulates motion with gaps between 30s & 4m and checks that the learned delay
+// settles around the 90th percentile of the gaps instead of shrinking to the minimum.
+func TestMotionHistoryDelayStable(t *testing.T) {
+	settings := &autodelay.Settings{}
+	settings.SetDefaults()
+
+	history := newMotionHistory(settings)
+	random := rand.New(rand.NewSource(1)) //nolint:gosec
+
+	const (
+		daytimeName = "day"
+		fallback    = 5 * time.Minute
+		minGap      = 30 * time.Second
+		maxGap      = 4 * time.Minute
+	)
+
+	lastMotion := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for idx := range 2000 {
+		delay := history.delay(daytimeName, fallback)
+
+		// after enough gaps, the learned delay must stay close to the 90th percentile of the gaps (3m39s)
+		if idx >= 500 && (delay < 3*time.Minute || delay > maxGap) {
+			t.Fatalf("learned delay %s after %d motion events is outside of [3m, 4m]", delay, idx)
+		}
+
+		gap := minGap + time.Duration(random.Int63n(int64(maxGap-minGap)))
+		motionAt := lastMotion.Add(gap)
+
+		// the lights were turned off after the delay if no motion was detected in between
+		var turnedOffAt time.Time
+		if gap > delay {
+			turnedOffAt = lastMotion.Add(delay)
+		}
+
+		history.observe(daytimeName, lastMotion, motionAt, turnedOffAt)
+
+		lastMotion = motionAt
+	}
+}
+
+// TestMotionHistoryObserveLateMotion checks that motion long after an automatic turn off is not learned as gap.
+func TestMotionHistoryObserveLateMotion(t *testing.T) {
+	settings := &autodelay.Settings{}
+	settings.SetDefaults()
+
+	history := newMotionHistory(settings)
+
+	lastMotion := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	turnedOffAt := lastMotion.Add(2 * time.Minute)
+
+	// back within bump_within - the delay was too short
+	history.observe("day", lastMotion, turnedOffAt.Add(settings.BumpWithin/2), turnedOffAt)
+	// someone entering the room an hour later
+	history.observe("day", lastMotion, turnedOffAt.Add(time.Hour), turnedOffAt)
+
+	if gaps := history.gaps["day"]; len(gaps) != 1 || gaps[0] != 2*time.Minute+settings.BumpWithin/2 {
+		t.Fatalf("expected only the gap of the motion within bump_within, got %v", gaps)
+	}
+}
+
+// TestMotionHistoryBumpDecays checks that bumps multiply the delay and decay back to the learned delay over time.
+func TestMotionHistoryBumpDecays(t *testing.T) {
+	settings := &autodelay.Settings{}
+	settings.SetDefaults()
+
+	history := newMotionHistory(settings)
+
+	const fallback = 4 * time.Minute
+
+	if factor := history.bump("day"); factor != settings.BumpFactor {
+		t.Fatalf("first bump factor %.4f, expected %.4f", factor, settings.BumpFactor)
+	}
+
+	// the first bump decayed slightly in between
+	if factor := history.bump("day"); math.Abs(factor-settings.BumpFactor*settings.BumpFactor) > 1e-6 {
+		t.Fatalf("second bump factor %.4f, expected %.4f", factor, settings.BumpFactor*settings.BumpFactor)
+	}
+
+	if delay := history.delay("day", fallback); (delay - time.Duration(float64(fallback)*settings.BumpFactor*settings.BumpFactor)).Abs() > time.Second {
+		t.Fatalf("bumped delay %s, expected %s x %.4f", delay, fallback, settings.BumpFactor*settings.BumpFactor)
+	}
+
+	// other daytimes are not bumped
+	if delay := history.delay("night", fallback); delay != fallback {
+		t.Fatalf("delay of another daytime %s, expected %s", delay, fallback)
+	}
+
+	tests := []struct {
+		age  time.Duration
+		want float64
+	}{
+		{autoDelayBumpHalfLife, 1 + (settings.BumpFactor*settings.BumpFactor-1)/2},
+		{2 * autoDelayBumpHalfLife, 1 + (settings.BumpFactor*settings.BumpFactor-1)/4},
+		{30 * autoDelayBumpHalfLife, 1},
+	}
+
+	for _, test := range tests {
+		bump := history.bumps["day"]
+		bump.at = time.Now().Add(-test.age)
+		history.bumps["day"] = bump
+
+		want := time.Duration(float64(fallback) * test.want)
+		if delay := history.delay("day", fallback); (delay - want).Abs() > time.Second {
+			t.Errorf("delay %s after %s, expected %s", delay, test.age, want)
+		}
+	}
+}
@@ -8,11 +8,14 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/benleb/automoli-go/internal/calendar"
 	"github.com/benleb/automoli-go/internal/homeassistant"
 	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/metrics"
 	"github.com/benleb/automoli-go/internal/models"
 	"github.com/benleb/automoli-go/internal/models/daytime"
 	"github.com/benleb/automoli-go/internal/models/flash"
+	"github.com/benleb/automoli-go/internal/models/sun"
 	"github.com/benleb/automoli-go/internal/style"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
@@ -20,6 +23,7 @@ import (
 	"github.com/go-co-op/gocron"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
 )
 
 var (
@@ -46,8 +50,18 @@ type AutoMoLi struct {
 	// channel for incoming events from Home Assistant
 	events chan *homeassistant.EventMsg
 
-	// a sensor -> room mapping to forward incoming events to the correct room.
-	roomSensorEvents map[homeassistant.EntityID]map[homeassistant.EventType]*Room
+	// location used to calculate sun-relative daytime start times
+	location *sun.Location
+
+	// calendars daytimes can be restricted to
+	calendars map[string]calendar.Calendar
+
+	// a sensor -> rooms mapping to forward incoming events to the correct room(s).
+	// a sensor may be used in multiple rooms, e.g. in open-plan rooms.
+	roomSensorEvents map[homeassistant.EntityID]map[homeassistant.EventType][]*Room
+
+	// an event type -> rooms mapping for events matched by the trigger rules of the rooms (e.g. zha_event)
+	roomTriggerEvents map[homeassistant.EventType][]*Room
 
 	triggerEvents mapset.Set[homeassistant.EventType]
 
@@ -84,9 +98,11 @@ func New() *AutoMoLi {
 			},
 		},
 
-		events:           make(chan *homeassistant.EventMsg),
-		roomSensorEvents: make(map[homeassistant.EntityID]map[homeassistant.EventType]*Room),
-		triggerEvents:    mapset.NewSet[homeassistant.EventType](),
+		events:            make(chan *homeassistant.EventMsg),
+		roomSensorEvents:  make(map[homeassistant.EntityID]map[homeassistant.EventType][]*Room),
+		roomTriggerEvents: make(map[homeassistant.EventType][]*Room),
+		triggerEvents:     mapset.NewSet[homeassistant.EventType](),
+		calendars:         make(map[string]calendar.Calendar),
 
 		daytimeSwitcher: gocron.NewScheduler(time.UTC),
 
@@ -97,7 +113,7 @@ func New() *AutoMoLi {
 	}
 
 	// unmarshal global configuration
-	if err := viper.UnmarshalKey("automoli", &aml.Config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(mapstructure.StringToTimeDurationHookFunc(), homeassistant.StringToEntityIDHookFunc()))); err != nil {
+	if err := viper.UnmarshalKey("automoli", &aml.Config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(daytime.StringToDelayHookFunc(), mapstructure.StringToTimeDurationHookFunc(), homeassistant.StringToEntityIDHookFunc()))); err != nil {
 		aml.Pr.With("err", err).Error("decoding automoli configuration failed")
 
 		return nil
@@ -113,6 +129,27 @@ func New() *AutoMoLi {
 
 	aml.ha = hass
 
+	// location for sun-relative daytimes - configured or from home assistant
+	if aml.location = aml.Location; aml.location == nil {
+		if haConfig, err := aml.ha.GetConfig(); err != nil {
+			aml.Pr.With("err", err).Warn("fetching location from home assistant failed")
+		} else {
+			aml.location = &sun.Location{Latitude: haConfig.Latitude, Longitude: haConfig.Longitude}
+		}
+	}
+
+	// calendars for weekday/holiday-aware daytimes
+	for name, source := range aml.Calendars {
+		cal, err := calendar.New(source, aml.ha)
+		if err != nil {
+			aml.Pr.With("err", err).Errorf("loading calendar %s from %s failed", style.Bold(name), source)
+
+			continue
+		}
+
+		aml.calendars[name] = cal
+	}
+
 	//
 	// rooms configuration
 
@@ -133,45 +170,117 @@ func New() *AutoMoLi {
 
 	// collect all trigger events & create room -> event mapping
 	for _, room := range aml.rooms {
-		// subscribe to xiaomi motion events
-		room.TriggerEvents.Add(homeassistant.EventXiaomiMotion)
-
-		// subscribe state_changed
+		// motion sensors report their state via state_changed if the motion states are set
+		sensorEvents := make([]homeassistant.EventType, 0, len(room.MotionEvents)+1)
 		if room.MotionStateOn != "" && room.MotionStateOff != "" {
-			room.TriggerEvents.Add(homeassistant.EventStateChanged)
+			sensorEvents = append(sensorEvents, homeassistant.EventStateChanged)
+		}
+
+		// create a sensor -> room mapping to forward incoming events to the correct room
+		for _, sensor := range room.MotionSensors {
+			eventTypes := slices.Clone(sensorEvents)
+
+			// motion events of the sensors (xiaomi motion events by default) - unless the sensor has its own triggers
+			if _, ok := room.MotionTriggers[sensor]; !ok {
+				eventTypes = append(eventTypes, room.MotionEvents...)
+			}
+
+			for _, eventType := range eventTypes {
+				room.TriggerEvents.Add(eventType)
+				aml.addRoomEntityEvent(sensor, eventType, room)
+			}
+		}
+
+		// create an event type -> room mapping for events matched by the triggers of single motion sensors
+		for _, sensorTriggers := range room.MotionTriggers {
+			for _, sensorTrigger := range sensorTriggers {
+				aml.addRoomTriggerEvent(sensorTrigger.EventType, room)
+			}
+		}
+
+		// create an event type -> room mapping for events matched by the room triggers & buttons
+		for _, roomTrigger := range room.Triggers {
+			aml.addRoomTriggerEvent(roomTrigger.EventType, room)
+		}
+
+		for _, roomButton := range room.Buttons {
+			aml.addRoomTriggerEvent(roomButton.EventType, room)
 		}
 
 		// add trigger events to global set
 		aml.triggerEvents = aml.triggerEvents.Union(room.TriggerEvents)
 
-		// create a sensor -> room mapping to forward incoming events to the correct room
-		for _, sensor := range room.MotionSensors {
-			for _, eventType := range room.TriggerEvents.ToSlice() {
-				if _, ok := aml.roomSensorEvents[sensor]; !ok {
-					aml.roomSensorEvents[sensor] = make(map[homeassistant.EventType]*Room)
-				}
+		// forward state changes of the room lights to detect manual control
+		for _, light := range room.Lights {
+			aml.addRoomEntityEvent(light, homeassistant.EventStateChanged, room)
+		}
+
+		// forward state changes of the humidity sensors to detect a fast rise of the humidity
+		if room.humidityRise != nil {
+			for _, sensor := range room.HumiditySensors {
+				aml.addRoomEntityEvent(sensor, homeassistant.EventStateChanged, room)
+			}
 
-				aml.roomSensorEvents[sensor][eventType] = room
+			// the reference sensor becoming unavailable releases the shower case
+			if referenceSensor := room.HumidityRise.ReferenceSensor; referenceSensor != nil {
+				aml.addRoomEntityEvent(*referenceSensor, homeassistant.EventStateChanged, room)
 			}
 		}
 
+		// forward state changes of the presence sensors - they hold the lights on while reporting presence
+		for _, sensor := range room.PresenceSensors {
+			aml.addRoomEntityEvent(sensor, homeassistant.EventStateChanged, room)
+		}
+
+		// forward state changes of the door sensors to track the occupancy
+		for _, sensor := range room.DoorSensors {
+			aml.addRoomEntityEvent(sensor, homeassistant.EventStateChanged, room)
+		}
+
+		// forward state changes of the night mode entity
+		if room.NightMode != nil {
+			aml.addRoomEntityEvent(room.NightMode.Entity, homeassistant.EventStateChanged, room)
+			aml.triggerEvents.Add(homeassistant.EventStateChanged)
+		}
+
 		// print room config
 		fmt.Println(room.GetFmtRoomConfig())
 	}
 
+	// log sensors triggering multiple rooms
+	aml.logSharedMotionSensors()
+
+	// forward only state changes of entities mapped to a room - unless triggers may match state changes of any entity
+	if len(aml.roomTriggerEvents[homeassistant.EventStateChanged]) == 0 {
+		aml.ha.ForwardStates(aml.stateChangedEntities())
+	}
+
 	// start handler for incoming events from Home Assistant
 	go aml.eventHandler()
 
-	// subscribe to events from Home Assistant
-	go aml.ha.SubscribeToEvents(aml.triggerEvents)
+	// subscribe to events from Home Assistant & to pause requests
+	go func() {
+		aml.ha.SubscribeToEvents(aml.triggerEvents)
+		aml.ha.SubscribeToEvent(homeassistant.EventAutoMoLiPause)
+	}()
 
 	// start daytime switcher
 	aml.daytimeSwitcher.StartAsync()
 
+	// start the http api serving the room states & controls
+	if aml.HTTP != nil && aml.HTTP.Listen != "" {
+		aml.startAPI(aml.HTTP.Listen)
+	}
+
+	// notify systemd (Type=notify & WatchdogSec)
+	go aml.systemdNotifier()
+
 	// start stats ticker regularly printing the number of received/processed events
 	go aml.statsTicker()
 
-	// get all lights from all rooms
+	// get all motion sensors & lights from all rooms
+	motionSensors := aml.motionSensors()
+
 	allLights := mapset.NewSet[homeassistant.EntityID]()
 	for _, room := range aml.rooms {
 		allLights = allLights.Union(mapset.NewSet[homeassistant.EntityID](room.Lights...))
@@ -183,7 +292,7 @@ func New() *AutoMoLi {
 	intro.WriteString(" " + style.DarkDivider.String() + " ")
 	// house id
 	intro.WriteString(" " + icons.Home + " ")
-	intro.WriteString(style.Bold(aml.hashedHouseID(len(aml.rooms), allLights.Cardinality(), len(aml.roomSensorEvents))) + " ")
+	intro.WriteString(style.Bold(aml.hashedHouseID(len(aml.rooms), allLights.Cardinality(), motionSensors.Cardinality())) + " ")
 	// rooms
 	intro.WriteString(" " + style.DarkDivider.String() + " ")
 	intro.WriteString(" " + icons.Door + " ")
@@ -197,7 +306,7 @@ func New() *AutoMoLi {
 	// sensors
 	intro.WriteString(" " + style.DarkDivider.String() + " ")
 	intro.WriteString(" " + icons.Motion + " ")
-	intro.WriteString(style.Bold(strconv.Itoa(len(aml.roomSensorEvents))))
+	intro.WriteString(style.Bold(strconv.Itoa(motionSensors.Cardinality())))
 	intro.WriteString(style.Gray(8).Render(" sensors "))
 	// version
 	intro.WriteString(" " + style.DarkDivider.String() + "  ")
@@ -213,6 +322,68 @@ func New() *AutoMoLi {
 	return aml
 }
 
+// addRoomEntityEvent adds a mapping to forward events of the given type and entity to the room.
+func (aml *AutoMoLi) addRoomEntityEvent(entityID homeassistant.EntityID, eventType homeassistant.EventType, room *Room) {
+	if _, ok := aml.roomSensorEvents[entityID]; !ok {
+		aml.roomSensorEvents[entityID] = make(map[homeassistant.EventType][]*Room)
+	}
+
+	if !slices.Contains(aml.roomSensorEvents[entityID][eventType], room) {
+		aml.roomSensorEvents[entityID][eventType] = append(aml.roomSensorEvents[entityID][eventType], room)
+	}
+}
+
+// addRoomTriggerEvent adds a mapping to forward events of the given type to the room if they match its triggers/buttons.
+func (aml *AutoMoLi) addRoomTriggerEvent(eventType homeassistant.EventType, room *Room) {
+	room.TriggerEvents.Add(eventType)
+
+	if !slices.Contains(aml.roomTriggerEvents[eventType], room) {
+		aml.roomTriggerEvents[eventType] = append(aml.roomTriggerEvents[eventType], room)
+	}
+}
+
+// logSharedMotionSensors logs motion sensors used in multiple rooms to spot accidental duplicates.
+func (aml *AutoMoLi) logSharedMotionSensors() {
+	sensorRooms := make(map[homeassistant.EntityID][]string)
+
+	for _, room := range aml.rooms {
+		for _, sensor := range room.MotionSensors {
+			if !slices.Contains(sensorRooms[sensor], room.Name) {
+				sensorRooms[sensor] = append(sensorRooms[sensor], room.Name)
+			}
+		}
+	}
+
+	for sensor, roomNames := range sensorRooms {
+		if len(roomNames) > 1 {
+			aml.Pr.Warnf("%s motion sensor %s is shared by %d rooms: %s", icons.Motion, sensor.FmtShort(), len(roomNames), style.Bold(strings.Join(roomNames, ", ")))
+		}
+	}
+}
+
+// stateChangedEntities returns all entities whose state changes are forwarded to a room.
+func (aml *AutoMoLi) stateChangedEntities() mapset.Set[homeassistant.EntityID] {
+	entities := mapset.NewSet[homeassistant.EntityID]()
+
+	for entityID, eventRooms := range aml.roomSensorEvents {
+		if len(eventRooms[homeassistant.EventStateChanged]) > 0 {
+			entities.Add(entityID)
+		}
+	}
+
+	return entities
+}
+
+// motionSensors returns all motion sensors of all rooms.
+func (aml *AutoMoLi) motionSensors() mapset.Set[homeassistant.EntityID] {
+	motionSensors := mapset.NewSet[homeassistant.EntityID]()
+	for _, room := range aml.rooms {
+		motionSensors = motionSensors.Union(mapset.NewSet[homeassistant.EntityID](room.MotionSensors...))
+	}
+
+	return motionSensors
+}
+
 // hashedHouseID creates a magic house id based on the number of rooms, lights and sensors.
 // The ID is a single, short, unique but also stable identifier for the current configuration of rooms, lights and sensors.
 func (aml *AutoMoLi) hashedHouseID(roomCount, lightCount, sensorCount int) string {
@@ -259,6 +430,11 @@ func (aml *AutoMoLi) statsTicker() {
 				fmtRoomEventCount.WriteString(icons.LightOn + " ")
 			}
 
+			// show the remaining pause time
+			if room.isPaused() {
+				fmtRoomEventCount.WriteString(icons.Pause + " " + room.pauseRemaining().Round(time.Second).String() + " ")
+			}
+
 			fmtRoomEventCount.WriteString(room.FmtShort())
 			fmtRoomEventCount.WriteString(style.Gray(6).Render(":"))
 			fmtRoomEventCount.WriteString(fmtStats(eventsReceived, eventsPerTime, room.style))
@@ -279,16 +455,109 @@ func (aml *AutoMoLi) eventHandler() {
 	for triggerEvent := range aml.events {
 		// count events
 		aml.eventsReceivedTotal.Add(1)
+		metrics.EventsReceived.Inc()
 
 		entityID := triggerEvent.Event.Data.EntityID
 
-		// get the room this event belongs to
-		if room, ok := aml.roomSensorEvents[entityID][triggerEvent.Event.Type]; ok {
+		// pause requests
+		if triggerEvent.Event.Type == homeassistant.EventAutoMoLiPause {
+			aml.handlePauseEvent(triggerEvent)
+
+			continue
+		}
+
+		// get the room(s) this event belongs to - by sensor & by trigger rules
+		rooms := slices.Clone(aml.roomSensorEvents[entityID][triggerEvent.Event.Type])
+
+		for _, room := range aml.roomTriggerEvents[triggerEvent.Event.Type] {
+			if !slices.Contains(rooms, room) && room.matchesEvent(triggerEvent) {
+				rooms = append(rooms, room)
+			}
+		}
+
+		if len(rooms) == 0 {
+			aml.Pr.Debugf("%s no room found for sensor %v", icons.Hae, entityID)
+
+			continue
+		}
+
+		for _, room := range rooms {
 			room.EventsChannel <- triggerEvent
 		}
+	}
+}
+
+// handlePauseEvent pauses the room given in the event data for the given duration.
+func (aml *AutoMoLi) handlePauseEvent(event *homeassistant.EventMsg) {
+	roomName, _ := event.Event.Data.Other["room"].(string)
+
+	var duration time.Duration
+
+	// duration as duration string like "2h" or in seconds
+	switch rawDuration := event.Event.Data.Other["duration"].(type) {
+	case string:
+		var err error
+
+		if duration, err = time.ParseDuration(rawDuration); err != nil {
+			aml.Pr.With("err", err).Warnf("%s invalid pause duration %s", icons.Pause, style.Bold(rawDuration))
+
+			return
+		}
+
+	case float64:
+		duration = time.Duration(rawDuration * float64(time.Second))
+
+	default:
+		aml.Pr.Warnf("%s pause event without duration: %+v", icons.Pause, event.Event.Data.Other)
+
+		return
+	}
+
+	if err := aml.PauseRoom(roomName, duration); err != nil {
+		aml.Pr.With("err", err).Warnf("%s pausing room %s failed", icons.Pause, style.Bold(roomName))
+	}
+}
 
-		aml.Pr.Debugf("%s no room found for sensor %v", icons.Hae, entityID)
+// PauseRoom pauses the room with the given name for the given duration (a non-positive duration resumes the room).
+func (aml *AutoMoLi) PauseRoom(roomName string, duration time.Duration) error {
+	room, err := aml.room(roomName)
+	if err != nil {
+		return err
 	}
+
+	room.Pause(duration)
+
+	return nil
+}
+
+// room returns the room with the given name (case-insensitive).
+func (aml *AutoMoLi) room(roomName string) (*Room, error) {
+	for _, room := range aml.rooms {
+		if strings.EqualFold(room.Name, roomName) {
+			return room, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", models.ErrUnknownRoom, roomName)
+}
+
+// hasCalendarEventOn checks if the calendar with the given name has an event on the day of the given date.
+func (aml *AutoMoLi) hasCalendarEventOn(calendarName string, date time.Time) bool {
+	cal, ok := aml.calendars[calendarName]
+	if !ok {
+		aml.Pr.Warnf("%s unknown calendar %s", icons.Hae, style.Bold(calendarName))
+
+		return false
+	}
+
+	hasEvent, err := cal.HasEventOn(date)
+	if err != nil {
+		aml.Pr.With("err", err).Warnf("checking calendar %s failed", style.Bold(calendarName))
+
+		return false
+	}
+
+	return hasEvent
 }
 
 // isDisabled checks if AutoMoLi is disabled by any entity or entity state.
@@ -297,11 +566,21 @@ func (aml *AutoMoLi) isDisabled() bool {
 }
 
 func (aml *AutoMoLi) disabledBy() map[homeassistant.EntityID]string {
+	return activeDisablers(aml.ha, aml.DisabledBy)
+}
+
+// activeDisablers returns the entities (and their states) that are currently in one of their disabling states.
+func activeDisablers(ha *homeassistant.HomeAssistant, disabledBy map[homeassistant.EntityID][]string) map[homeassistant.EntityID]string {
 	activeDisabler := make(map[homeassistant.EntityID]string)
 
-	for disablingEntityID, disablingStates := range aml.DisabledBy {
-		if entityState := aml.ha.GetState(disablingEntityID).State; mapset.NewSet[string](disablingStates...).Contains(entityState) {
-			activeDisabler[disablingEntityID] = entityState
+	for disablingEntityID, disablingStates := range disabledBy {
+		entityState := ha.GetState(disablingEntityID)
+		if entityState == nil {
+			continue
+		}
+
+		if mapset.NewSet[string](disablingStates...).Contains(entityState.State) {
+			activeDisabler[disablingEntityID] = entityState.State
 		}
 	}
 
@@ -2,30 +2,70 @@ package automoli
 
 import (
 	"math"
+	"strings"
 	"time"
 
 	"github.com/benleb/automoli-go/internal/homeassistant"
 	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/models/adaptive"
+	"github.com/benleb/automoli-go/internal/models/autodelay"
+	"github.com/benleb/automoli-go/internal/models/button"
+	"github.com/benleb/automoli-go/internal/models/condition"
 	"github.com/benleb/automoli-go/internal/models/daytime"
+	"github.com/benleb/automoli-go/internal/models/dim"
+	"github.com/benleb/automoli-go/internal/models/expression"
+	"github.com/benleb/automoli-go/internal/models/sun"
+	"github.com/benleb/automoli-go/internal/models/trigger"
 	"github.com/benleb/automoli-go/internal/style"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/mitchellh/mapstructure"
+	"golang.org/x/exp/slices"
 )
 
+// refreshDaytimesTag is the scheduler tag of the daily daytime refresh job.
+const refreshDaytimesTag = "refresh-daytimes"
+
+// defaultButtonOffDuration is the time motion is ignored after the lights were turned off by a button.
+const defaultButtonOffDuration = 10 * time.Minute
+
+// defaultNotifyMessage is the message of the forgotten lights notification if not configured.
+const defaultNotifyMessage = "💡 the lights in {room} are on without motion"
+
+// defaultDimBrightnessStepPct is used to dim the lights if the step method is used without a step size.
+const defaultDimBrightnessStepPct = -50
+
 type Config struct {
 	// DisabledBy is a map of entities that control the state of AutoMoLi
 	// if any entity is in state 'off' - AutoMoLi will be treated as 'off' too (won't react to any events)
 	DisabledBy map[homeassistant.EntityID][]string `mapstructure:"disabled_by,omitempty"`
 
+	// Location is used to calculate sun-relative daytime start times (fetched from Home Assistant if not set)
+	Location *sun.Location `mapstructure:"location,omitempty"`
+
+	// Calendars are named calendars (calendar.* entities or local ics files) daytimes can be restricted to
+	Calendars map[string]string `mapstructure:"calendars,omitempty"`
+
 	// StatsInterval is the interval in which the stats ticker will print the stats line
 	StatsInterval time.Duration `mapstructure:"stats_interval,omitempty"`
 
+	// HTTP configures the http api serving the room states & controls (disabled if not set)
+	HTTP *HTTPConfig `mapstructure:"http,omitempty"`
+
 	// LightConfiguration is the default light configuration for all rooms
 	daytime.LightConfiguration `mapstructure:",squash"`
 }
 
+// HTTPConfig holds the settings of the http api.
+type HTTPConfig struct {
+	// Listen is the address the http api listens on, e.g. ":8337" or "127.0.0.1:8337"
+	Listen string `mapstructure:"listen"`
+
+	// Token is required as bearer token for the controls (POST requests) if set
+	Token string `mapstructure:"token,omitempty"`
+}
+
 func parseRooms(aml *AutoMoLi, roomConfig []interface{}) []*Room {
 	rooms := make([]*Room, 0)
 
@@ -39,8 +79,6 @@ func parseRooms(aml *AutoMoLi, roomConfig []interface{}) []*Room {
 
 		// create a room
 		if room := newRoom(aml, rawRoom); room != nil {
-			room.aml = aml
-
 			// on (re)start, we assume that we turned on the lights if they are on
 			room.turnedOnByAutoMoLi = room.isLightOn()
 
@@ -50,6 +88,16 @@ func parseRooms(aml *AutoMoLi, roomConfig []interface{}) []*Room {
 			// schedule daytime switches
 			go room.scheduleDaytimeSwitches()
 
+			// watch for lights that are on for too long without motion
+			if room.MaxOnDuration > 0 || room.Notify.IsEnabled() {
+				go room.forgottenLightsWatcher()
+			}
+
+			// adjust lights that are on to the adaptive values
+			if room.Adaptive.IsEnabled() && room.Adaptive.NudgeEvery > 0 {
+				go room.adaptiveNudger()
+			}
+
 			// initial setup depending on current light state
 			if room.isLightOn() {
 				room.pr.Infof("%s lights on! starting the timer...", icons.LightOn)
@@ -64,16 +112,65 @@ func parseRooms(aml *AutoMoLi, roomConfig []interface{}) []*Room {
 	return rooms
 }
 
+// hasCalendarsOf checks if all calendars used by the daytimes of the room are configured.
+func (aml *AutoMoLi) hasCalendarsOf(room *Room) bool {
+	for _, dt := range room.Daytimes {
+		for _, calendarName := range []string{dt.Calendar, dt.ExceptCalendar} {
+			if _, ok := aml.calendars[calendarName]; calendarName != "" && !ok {
+				room.pr.Errorf("❌ daytime %s uses unknown calendar %s", style.Bold(dt.Name), style.Bold(calendarName))
+
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// decodeRoom decodes the raw room config into the room and returns the metadata with the unused keys.
+// strict decoding (used by the validate command) rejects invalid targets instead of skipping them and returns the
+// unknown keys as errors - the metadata only contains them if there are no other errors.
+func decodeRoom(rawRoom map[string]interface{}, room *Room, strict bool) (mapstructure.Metadata, error) {
+	var metadata mapstructure.Metadata
+
+	decodeHooks := []mapstructure.DecodeHookFunc{
+		mapstructure.StringToTimeHookFunc("15:04"),
+		daytime.StringToDelayHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.TextUnmarshallerHookFunc(),
+		homeassistant.StringToEntityIDHookFunc(),
+	}
+
+	if strict {
+		decodeHooks = append([]mapstructure.DecodeHookFunc{daytime.StrictTargetsHookFunc()}, decodeHooks...)
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:  mapstructure.ComposeDecodeHookFunc(decodeHooks...),
+		Result:      room,
+		Metadata:    &metadata,
+		ErrorUnused: strict,
+	})
+	if err != nil {
+		return metadata, err
+	}
+
+	return metadata, decoder.Decode(rawRoom)
+}
+
 func newRoom(aml *AutoMoLi, rawRoom map[string]interface{}) *Room {
 	// room with default settings
 	room := &Room{
-		ha: aml.ha,
+		aml: aml,
+		ha:  aml.ha,
 
 		LightConfiguration: daytime.LightConfiguration{
 			Delay:      aml.Delay,
 			Transition: aml.Transition,
 			Flash:      aml.Flash,
 
+			TransitionOnDaytimeSwitch: aml.TransitionOnDaytimeSwitch,
+
 			ManualModeConfiguration: daytime.ManualModeConfiguration{
 				LockConfiguration: aml.LockConfiguration,
 				LockState:         aml.LockState,
@@ -85,22 +182,8 @@ func newRoom(aml *AutoMoLi, rawRoom map[string]interface{}) *Room {
 		EventsChannel: make(chan *homeassistant.EventMsg, 16),
 	}
 
-	// create rooms decoder
-	var metadata mapstructure.Metadata
-
-	decoder, _ := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
-		DecodeHook: mapstructure.ComposeDecodeHookFunc(
-			mapstructure.StringToTimeHookFunc("15:04"),
-			mapstructure.StringToTimeDurationHookFunc(),
-			mapstructure.TextUnmarshallerHookFunc(),
-			homeassistant.StringToEntityIDHookFunc(),
-		),
-		Result:   &room,
-		Metadata: &metadata,
-	})
-
 	// decode room config
-	err := decoder.Decode(rawRoom)
+	metadata, err := decodeRoom(rawRoom, room, false)
 	if err != nil {
 		log.With("err", err).Error("❌ decoding room config failed")
 
@@ -129,8 +212,18 @@ func newRoom(aml *AutoMoLi, rawRoom map[string]interface{}) *Room {
 
 		return nil
 
-	case len(room.MotionSensors) == 0:
-		room.pr.Errorf("❌ no motion sensors configured for %+v | disabling %s for this room", style.Bold(room.Name), AppName)
+	case len(room.MotionSensors) == 0 && len(room.PresenceSensors) == 0 && len(room.Triggers) == 0:
+		room.pr.Errorf("❌ no motion/presence sensors or triggers configured for %+v | disabling %s for this room", style.Bold(room.Name), AppName)
+
+		return nil
+
+	case !aml.hasCalendarsOf(room):
+		room.pr.Errorf("❌ unknown calendar configured for %+v | disabling %s for this room", style.Bold(room.Name), AppName)
+
+		return nil
+
+	case aml.location == nil && slices.ContainsFunc(room.Daytimes, func(dt *daytime.Daytime) bool { return dt.Start.IsSunRelative() }):
+		room.pr.Errorf("❌ sun-relative daytimes need a location (automoli.location or from home assistant) for %+v | disabling %s for this room", style.Bold(room.Name), AppName)
 
 		return nil
 
@@ -140,51 +233,298 @@ func newRoom(aml *AutoMoLi, rawRoom map[string]interface{}) *Room {
 		return nil
 	}
 
+	//
+	// triggers
+
+	// motion sensors send xiaomi motion events by default
+	if room.MotionEvents == nil {
+		room.MotionEvents = []homeassistant.EventType{homeassistant.EventXiaomiMotion}
+	}
+
+	// triggers of motion sensors replace the motion events of the sensor - only configured sensors are valid
+	for sensor, sensorTriggers := range room.MotionTriggers {
+		if !slices.Contains(room.MotionSensors, sensor) {
+			room.pr.Warnf("❌ ignoring triggers of %s | not a motion sensor of this room", sensor.FmtShort())
+
+			delete(room.MotionTriggers, sensor)
+
+			continue
+		}
+
+		room.MotionTriggers[sensor] = slices.DeleteFunc(sensorTriggers, func(sensorTrigger *trigger.Trigger) bool {
+			if sensorTrigger == nil || sensorTrigger.EventType == "" {
+				room.pr.Warnf("❌ ignoring trigger of %s without event type: %+v", sensor.FmtShort(), sensorTrigger)
+
+				return true
+			}
+
+			return false
+		})
+	}
+
+	// triggers without an event type would never match
+	room.Triggers = slices.DeleteFunc(room.Triggers, func(roomTrigger *trigger.Trigger) bool {
+		if roomTrigger == nil || roomTrigger.EventType == "" {
+			room.pr.Warnf("❌ ignoring trigger without event type: %+v", roomTrigger)
+
+			return true
+		}
+
+		return false
+	})
+
+	// buttons need a valid action
+	room.Buttons = slices.DeleteFunc(room.Buttons, func(roomButton *button.Button) bool {
+		if roomButton == nil || roomButton.EventType == "" || !roomButton.Action.IsValid() {
+			room.pr.Warnf("❌ ignoring button without event type or with invalid action: %+v", roomButton)
+
+			return true
+		}
+
+		if roomButton.Action == button.Off && roomButton.Duration == 0 {
+			roomButton.Duration = defaultButtonOffDuration
+		}
+
+		return false
+	})
+
+	//
+	// conditions
+
+	room.Conditions = room.validConditions(room.Conditions)
+
+	//
+	// humidity
+
+	if room.HumidityRise.IsEnabled() {
+		room.humidityRise = newHumidityRiseTracker(room.HumidityRise)
+	}
+
+	//
+	// adaptive
+
+	if room.Adaptive.IsEnabled() {
+		room.Adaptive.SetDefaults()
+
+		switch {
+		case room.Adaptive.Mode != adaptive.Sun && room.Adaptive.Mode != adaptive.Curve:
+			room.pr.Warnf("❌ invalid adaptive mode %s for %s | disabling adaptive lighting for this room", style.Bold(string(room.Adaptive.Mode)), style.Bold(room.Name))
+
+			room.Adaptive = nil
+
+		case room.Adaptive.Mode == adaptive.Sun && aml.location == nil:
+			room.pr.Warnf("❌ adaptive mode %s needs a location (automoli.location or from home assistant) for %s | disabling adaptive lighting for this room", style.Bold(string(adaptive.Sun)), style.Bold(room.Name))
+
+			room.Adaptive = nil
+
+		case room.Adaptive.Mode == adaptive.Sun && slices.ContainsFunc(room.Daytimes, setsAdaptiveValues):
+			room.pr.Warnf("%s adaptive mode %s replaces the brightness & color temperature of the daytimes in %s", icons.Sun, style.Bold(string(adaptive.Sun)), style.Bold(room.Name))
+		}
+	}
+
+	//
+	// forgotten lights
+
+	if room.Notify != nil {
+		if _, _, err := room.Notify.DomainService(); err != nil || !room.Notify.IsEnabled() {
+			room.pr.Warnf("❌ invalid notify service %s or threshold %s | disabling notifications for this room", style.Bold(room.Notify.Service), style.Bold(room.Notify.After.String()))
+
+			room.Notify = nil
+		} else if room.Notify.Message == "" {
+			room.Notify.Message = defaultNotifyMessage
+		}
+	}
+
+	//
+	// doors
+
+	// binary door sensors are off while the door is closed
+	if room.DoorStateClosed == "" {
+		room.DoorStateClosed = "off"
+	}
+
+	//
+	// dim
+
+	if room.Dim != nil {
+		switch room.Dim.Method {
+		case "":
+			// dim in steps if a step is configured, otherwise fade out the lights
+			room.Dim.Method = dim.Transition
+			if room.Dim.BrightnessStepPct != 0 {
+				room.Dim.Method = dim.Step
+			}
+
+		case dim.Step, dim.Transition:
+
+		default:
+			room.pr.Warnf("❌ invalid dim method %s for %s | disabling dimming for this room", style.Bold(string(room.Dim.Method)), style.Bold(room.Name))
+
+			room.Dim = nil
+		}
+	}
+
+	if room.Dim != nil && room.Dim.Method == dim.Step && room.Dim.BrightnessStepPct == 0 {
+		room.Dim.BrightnessStepPct = defaultDimBrightnessStepPct
+	}
+
 	//
 	// daytimes
 
 	// settings
 	for _, currentDaytime := range room.Daytimes {
-		// set targets to room lights if not explicitly set
-		if len(currentDaytime.Targets) == 0 {
-			currentDaytime.Targets = room.Lights
+		room.applyDaytimeDefaults(currentDaytime)
+	}
+
+	//
+	// night mode
+
+	if room.NightMode != nil {
+		if room.NightMode.Entity == (homeassistant.EntityID{}) {
+			room.pr.Warnf("❌ no night mode entity configured for %s | disabling night mode for this room", style.Bold(room.Name))
+
+			room.NightMode = nil
+		} else {
+			if room.NightMode.Name == "" {
+				room.NightMode.Name = "night mode"
+			}
+
+			if len(room.NightMode.States) == 0 {
+				room.NightMode.States = []string{"on"}
+			}
+
+			room.applyDaytimeDefaults(&room.NightMode.Daytime)
+
+			// initial night mode state
+			if entityState := room.ha.GetState(room.NightMode.Entity); entityState != nil {
+				room.nightModeActive.Store(slices.Contains(room.NightMode.States, entityState.State))
+			}
+		}
+	}
+
+	//
+	// auto delay
+
+	if room.usesAutoDelay() {
+		if room.AutoDelay == nil {
+			room.AutoDelay = &autodelay.Settings{}
 		}
 
-		// set daytime off-delay
-		if currentDaytime.Delay == 0 {
-			currentDaytime.Delay = room.Delay
+		room.AutoDelay.SetDefaults()
+
+		room.motionHistory = newMotionHistory(room.AutoDelay)
+	}
+
+	return room
+}
+
+// usesAutoDelay checks if the room, any daytime or the night mode learns the delay from the motion history.
+func (r *Room) usesAutoDelay() bool {
+	if r.IsAutoDelay() || (r.NightMode != nil && r.NightMode.IsAutoDelay()) {
+		return true
+	}
+
+	return slices.ContainsFunc(r.Daytimes, func(dt *daytime.Daytime) bool { return dt.IsAutoDelay() })
+}
+
+// validConditions validates the conditions and drops (and logs) the invalid ones.
+func (r *Room) validConditions(conditions []*condition.Condition) []*condition.Condition {
+	return slices.DeleteFunc(conditions, func(cond *condition.Condition) bool {
+		if cond == nil {
+			return true
 		}
 
-		// if a custom service data is set, we use it
-		serviceData := make(map[string]interface{})
+		if err := cond.Validate(); err != nil {
+			r.pr.With("err", err).Warnf("❌ ignoring condition %s", cond)
 
-		if len(currentDaytime.ServiceData) > 0 {
-			serviceData = currentDaytime.ServiceData
+			return true
 		}
 
-		// set daytime transition times
-		if currentDaytime.Transition == 0 {
-			currentDaytime.Transition = room.Transition
+		return false
+	})
+}
+
+// applyDaytimeDefaults fills the unset settings of the daytime with the room settings.
+func (r *Room) applyDaytimeDefaults(currentDaytime *daytime.Daytime) {
+	// set targets to room lights if not explicitly set
+	if len(currentDaytime.Targets) == 0 {
+		currentDaytime.Targets = r.Lights
+	}
+
+	// state changes of the room lights caused by scenes & groups are our own too
+	for _, target := range currentDaytime.Targets {
+		if !slices.Contains(r.Lights, target) {
+			r.ha.Covers(target, r.Lights)
 		}
+	}
+
+	// set daytime off-delay
+	if currentDaytime.Delay == 0 {
+		currentDaytime.Delay = r.Delay
+	}
 
-		// service_data takes precedence over transition time wrapper field
-		if _, ok := serviceData["transition"]; !ok {
-			serviceData["transition"] = currentDaytime.Transition.Seconds()
+	// if a custom service data is set, we use it
+	serviceData := make(map[string]interface{})
+
+	if len(currentDaytime.ServiceData) > 0 {
+		serviceData = currentDaytime.ServiceData
+	}
+
+	// set daytime transition times
+	if currentDaytime.Transition == 0 {
+		currentDaytime.Transition = r.Transition
+	}
+
+	// apply the daytime to lights that are already on when switching to it
+	if currentDaytime.TransitionOnDaytimeSwitch == nil {
+		currentDaytime.TransitionOnDaytimeSwitch = r.TransitionOnDaytimeSwitch
+	}
+
+	// service_data takes precedence over transition time wrapper field
+	if _, ok := serviceData["transition"]; !ok {
+		serviceData["transition"] = currentDaytime.Transition.Seconds()
+	}
+
+	// set brightness_pct
+	if currentDaytime.BrightnessPct != nil && *currentDaytime.BrightnessPct > 0 {
+		// restrict brightness to 0-100
+		brightnessPct := uint8(math.Min(math.Max(float64(*currentDaytime.BrightnessPct), 0), 100))
+
+		// service_data takes precedence over brightness wrapper field
+		if _, ok := serviceData["brightness_pct"]; !ok {
+			serviceData["brightness_pct"] = brightnessPct
+		}
+	}
+
+	// set color_temp_kelvin
+	if currentDaytime.ColorTempKelvin != nil {
+		// service_data takes precedence over color temperature wrapper field
+		if _, ok := serviceData["color_temp_kelvin"]; !ok {
+			serviceData["color_temp_kelvin"] = *currentDaytime.ColorTempKelvin
 		}
+	}
 
-		// set brightness_pct
-		if currentDaytime.BrightnessPct != nil && *currentDaytime.BrightnessPct > 0 {
-			// restrict brightness to 0-100
-			brightnessPct := uint8(math.Min(math.Max(float64(*currentDaytime.BrightnessPct), 0), 100))
+	// compile expressions like "=num('sensor.lux') < 5 ? 20 : 60"
+	for key, value := range serviceData {
+		rawExpression, ok := value.(string)
+		if !ok || !strings.HasPrefix(rawExpression, expression.Prefix) {
+			continue
+		}
 
-			// service_data takes precedence over brightness wrapper field
-			if _, ok := serviceData["brightness_pct"]; !ok {
-				serviceData["brightness_pct"] = brightnessPct
-			}
+		compiledExpression, err := expression.Compile(rawExpression)
+		if err != nil {
+			r.pr.With("err", err).Errorf("❌ invalid %s expression in daytime %s | ignoring %s", style.Bold(key), style.Bold(currentDaytime.Name), style.Bold(key))
+
+			delete(serviceData, key)
+
+			continue
 		}
 
-		currentDaytime.ServiceData = serviceData
+		serviceData[key] = compiledExpression
 	}
 
-	return room
+	currentDaytime.ServiceData = serviceData
+
+	// drop invalid conditions
+	currentDaytime.Conditions = r.validConditions(currentDaytime.Conditions)
 }
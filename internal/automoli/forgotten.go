@@ -0,0 +1,147 @@
+package automoli
+
+import (
+	"time"
+
+	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/models/flash"
+	"github.com/benleb/automoli-go/internal/style"
+)
+
+// forgottenLightsCheckEvery is the interval the max on duration & the notification threshold are checked in.
+const forgottenLightsCheckEvery = 30 * time.Second
+
+// forgottenLightsWatcher periodically checks for lights that are on for too long without motion.
+func (r *Room) forgottenLightsWatcher() {
+	ticker := time.NewTicker(forgottenLightsCheckEvery)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		r.checkForgottenLights()
+	}
+}
+
+// lightsOnSince returns the time the lights were turned on - or first seen on if they were on before AutoMoLi started.
+func (r *Room) lightsOnSince() time.Time {
+	if r.lastSwitchedOn.IsZero() {
+		return r.seenOnAt
+	}
+
+	return r.lastSwitchedOn
+}
+
+// noMotionFor returns the time since the last motion or since the lights were turned on (whatever happened last).
+func (r *Room) noMotionFor() time.Duration {
+	if lightsOnSince := r.lightsOnSince(); !r.lastMotion.After(lightsOnSince) {
+		return time.Since(lightsOnSince)
+	}
+
+	return time.Since(r.lastMotion)
+}
+
+// checkForgottenLights sends the forgotten lights notification and turns off the lights after the max on duration.
+func (r *Room) checkForgottenLights() {
+	r.Lock()
+	defer r.Unlock()
+
+	if !r.isLightOn() {
+		r.forgottenNotified = false
+		r.maxOnWarnedAt = time.Time{}
+		r.seenOnAt = time.Time{}
+
+		return
+	}
+
+	// lights were already on when AutoMoLi started
+	if r.lastSwitchedOn.IsZero() && r.seenOnAt.IsZero() {
+		r.seenOnAt = time.Now()
+	}
+
+	noMotionFor := r.noMotionFor()
+
+	// notify once until the next motion
+	if r.Notify.IsEnabled() && !r.forgottenNotified && noMotionFor >= r.Notify.After {
+		r.forgottenNotified = true
+
+		go r.notifyForgottenLights(noMotionFor)
+	}
+
+	switch {
+	// max on duration not configured or not reached yet
+	case r.MaxOnDuration <= 0 || time.Since(r.lightsOnSince()) < r.MaxOnDuration:
+		return
+
+	// disabled or paused rooms are left alone
+	case r.isDisabled() || r.isPaused():
+		return
+
+	// someone is still in the room
+	case r.isPresenceDetected() || noMotionFor < r.GetActiveDelay():
+		return
+
+	// already warned - the lights will be turned off after the warning
+	case !r.maxOnWarnedAt.IsZero():
+		return
+	}
+
+	// turn off without a warning
+	if r.MaxOnWarning <= 0 {
+		r.turnOffAfterMaxOn()
+
+		return
+	}
+
+	r.maxOnWarnedAt = time.Now()
+
+	r.ha.TurnOn(r.lightsOn(), map[string]interface{}{"flash": flash.Short})
+
+	r.pr.Printf("%s on for %s %s turning off in %s", icons.Alarm, style.Bold(time.Since(r.lightsOnSince()).Round(time.Second).String()), style.DarkDivider.String(), style.Bold(r.MaxOnWarning.String()))
+
+	r.maxOnTimer = time.AfterFunc(r.MaxOnWarning, r.enforceMaxOn)
+}
+
+// enforceMaxOn turns off the lights after the warning unless motion was detected since.
+func (r *Room) enforceMaxOn() {
+	r.Lock()
+	defer r.Unlock()
+
+	switch {
+	// lights were turned off in the meantime
+	case r.maxOnWarnedAt.IsZero() || !r.isLightOn():
+		return
+
+	case r.lastMotion.After(r.maxOnWarnedAt):
+		r.pr.Printf("%s motion after the max on warning %s keeping the lights on", icons.Motion, style.DarkDivider.String())
+
+		r.maxOnWarnedAt = time.Time{}
+
+		return
+	}
+
+	r.turnOffAfterMaxOn()
+}
+
+// turnOffAfterMaxOn turns off the lights regardless of any locks.
+func (r *Room) turnOffAfterMaxOn() {
+	r.pr.Printf("%s max on duration of %s reached %s turning off the lights", icons.Alarm, style.Bold(r.MaxOnDuration.String()), style.DarkDivider.String())
+
+	r.turnLightsOff(time.Now())
+}
+
+// notifyForgottenLights calls the configured notify service.
+func (r *Room) notifyForgottenLights(noMotionFor time.Duration) {
+	serviceDomain, notifyService, err := r.Notify.DomainService()
+	if err != nil {
+		r.pr.Warnf("❌ notification failed: %+v", err)
+
+		return
+	}
+
+	if err := r.ha.CallService(serviceDomain, notifyService, r.Notify.ServiceData(r.Name)); err != nil {
+		r.pr.Warnf("❌ notification via %s failed: %+v", style.Bold(r.Notify.Service), err)
+
+		return
+	}
+
+	r.pr.Printf("%s lights on without motion for %s %s notified via %s", icons.LightOn, style.Bold(noMotionFor.Round(time.Second).String()), style.DarkDivider.String(), style.Bold(r.Notify.Service))
+}
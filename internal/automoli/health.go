@@ -0,0 +1,112 @@
+package automoli
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/systemd"
+)
+
+// readyCheckEvery is the interval the readiness is checked in until systemd is notified.
+const readyCheckEvery = time.Second
+
+// healthResponse is the json response of the health endpoints.
+type healthResponse struct {
+	Status string `json:"status"`
+
+	homeassistant.Health
+
+	MissingSubscriptions []string `json:"missing_subscriptions"`
+	UptimeSeconds        float64  `json:"uptime_seconds"`
+}
+
+func (aml *AutoMoLi) healthResponse(ok bool) healthResponse {
+	health := aml.ha.Health()
+
+	status := "ok"
+	if !ok {
+		status = "unavailable"
+	}
+
+	return healthResponse{
+		Status:               status,
+		Health:               health,
+		MissingSubscriptions: health.MissingSubscriptions(),
+		UptimeSeconds:        time.Since(aml.startTime).Seconds(),
+	}
+}
+
+// handleHealthz reports if AutoMoLi still receives messages from Home Assistant (liveness).
+func (aml *AutoMoLi) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	alive := aml.ha.Health().IsAlive()
+
+	status := http.StatusOK
+	if !alive {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, aml.healthResponse(alive))
+}
+
+// handleReadyz reports if AutoMoLi is connected, authenticated & subscribed to all events (readiness).
+func (aml *AutoMoLi) handleReadyz(w http.ResponseWriter, _ *http.Request) {
+	ready := aml.ha.Health().IsReady()
+
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, aml.healthResponse(ready))
+}
+
+// systemdNotifier notifies systemd when AutoMoLi is ready and keeps the systemd watchdog happy while it is alive.
+func (aml *AutoMoLi) systemdNotifier() {
+	notified, err := systemd.Notify(systemd.Status("starting"))
+	if err != nil {
+		aml.Pr.With("err", err).Warn("notifying systemd failed")
+	}
+
+	// not running as systemd notify service
+	if !notified {
+		return
+	}
+
+	// wait until connected & subscribed
+	for !aml.ha.Health().IsReady() {
+		time.Sleep(readyCheckEvery)
+	}
+
+	if notified, err := systemd.Notify(systemd.Ready + "\n" + systemd.Status("ready | "+strconv.Itoa(len(aml.rooms))+" rooms")); err != nil || !notified {
+		return
+	}
+
+	aml.Pr.Infof("%s notified systemd", icons.Rocket)
+
+	watchdogInterval := systemd.WatchdogInterval()
+	if watchdogInterval <= 0 {
+		return
+	}
+
+	// notify twice per watchdog interval as recommended by systemd
+	ticker := time.NewTicker(watchdogInterval / 2)
+	defer ticker.Stop()
+
+	aml.Pr.Infof("%s systemd watchdog enabled | interval: %s", icons.Watchdog, watchdogInterval)
+
+	for range ticker.C {
+		// stop petting the watchdog if no messages are received anymore - systemd restarts us
+		if !aml.ha.Health().IsAlive() {
+			aml.Pr.Warnf("%s no messages from home assistant | skipping systemd watchdog notification", icons.Watchdog)
+
+			continue
+		}
+
+		if _, err := systemd.Notify(systemd.Watchdog); err != nil {
+			aml.Pr.With("err", err).Warn("notifying systemd watchdog failed")
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package automoli
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/models/humidity"
+	"github.com/benleb/automoli-go/internal/style"
+	"golang.org/x/exp/slices"
+)
+
+// defaultHumidityRiseWithin is the time window for the humidity rise detection if not configured.
+const defaultHumidityRiseWithin = 10 * time.Minute
+
+type humiditySample struct {
+	value float64
+	at    time.Time
+}
+
+// humidityRiseTracker remembers the humidity of the last minutes to detect a fast rise (the "shower case").
+// once triggered, it stays active until the humidity falls below the baseline plus the hysteresis margin.
+type humidityRiseTracker struct {
+	settings *humidity.Rise
+
+	// humidity samples within the detection window
+	samples []humiditySample
+
+	// baseline remembered when the rise was detected
+	baseline float64
+	// active is true while the shower case is active
+	active bool
+
+	mu sync.Mutex
+}
+
+func newHumidityRiseTracker(settings *humidity.Rise) *humidityRiseTracker {
+	if settings.Within <= 0 {
+		settings.Within = defaultHumidityRiseWithin
+	}
+
+	return &humidityRiseTracker{settings: settings}
+}
+
+// update adds a humidity sample and returns if the shower case is active and if it was (de)activated by this sample.
+// if a reference humidity is given, it's used as baseline instead of the lowest humidity within the window.
+func (t *humidityRiseTracker) update(value float64, at time.Time, reference *float64) (bool, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// forget samples outside the window
+	t.samples = slices.DeleteFunc(t.samples, func(sample humiditySample) bool {
+		return at.Sub(sample.at) > t.settings.Within
+	})
+
+	t.samples = append(t.samples, humiditySample{value: value, at: at})
+
+	if t.active {
+		baseline := t.baseline
+		if reference != nil {
+			baseline = *reference
+		}
+
+		// release only after the humidity fell back close to the baseline
+		if value < baseline+t.settings.Margin() {
+			t.active = false
+
+			return false, true
+		}
+
+		return true, false
+	}
+
+	baseline := value
+	if reference != nil {
+		baseline = *reference
+	} else {
+		for _, sample := range t.samples {
+			baseline = min(baseline, sample.value)
+		}
+	}
+
+	if value-baseline >= t.settings.Rise {
+		t.active, t.baseline = true, baseline
+
+		return true, true
+	}
+
+	return false, false
+}
+
+// isActive checks if the shower case is active.
+func (t *humidityRiseTracker) isActive() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.active
+}
+
+// reset forgets all samples and releases the shower case, returns if it was active.
+func (t *humidityRiseTracker) reset() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	wasActive := t.active
+	t.samples, t.active = nil, false
+
+	return wasActive
+}
+
+// isHumidityRising checks if the humidity rose fast and has not yet fallen back (the "shower case").
+func (r *Room) isHumidityRising() bool {
+	return r.humidityRise != nil && r.humidityRise.isActive()
+}
+
+// handleHumidityEvent records the humidity on state changes of the humidity sensors to detect a fast rise.
+func (r *Room) handleHumidityEvent(event *homeassistant.EventMsg) {
+	if r.humidityRise == nil || event.Event.Type != homeassistant.EventStateChanged {
+		return
+	}
+
+	var reference *float64
+
+	if referenceSensor := r.HumidityRise.ReferenceSensor; referenceSensor != nil {
+		referenceHumidity, ok := r.numericState(*referenceSensor)
+		if !ok {
+			// without the reference there is no baseline - start over once it's back
+			if r.humidityRise.reset() {
+				r.pr.Printf("%s humidity rise %s | reference sensor %s unavailable", icons.Splash, style.Bold("released"), referenceSensor.FmtShort())
+			}
+
+			return
+		}
+
+		reference = &referenceHumidity
+	}
+
+	// changes of the reference sensor are no samples
+	if !slices.Contains(r.HumiditySensors, event.Event.Data.EntityID) {
+		return
+	}
+
+	sensor, currentHumidity := r.maxHumidity()
+	if sensor == (homeassistant.EntityID{}) {
+		return
+	}
+
+	active, changed := r.humidityRise.update(currentHumidity, event.Event.TimeFired, reference)
+
+	if changed {
+		state := style.Bold("released")
+		if active {
+			state = style.Bold("detected")
+		}
+
+		r.pr.Printf("%s humidity rise %s | %s %.1f%% %s baseline %s", icons.Splash, state, sensor.FmtShort(), currentHumidity, style.DarkDivider.String(), r.fmtHumidityBaseline(reference))
+	}
+}
+
+// isHumidityReference checks if the entity is the reference sensor of the humidity rise detection.
+func (r *Room) isHumidityReference(entityID homeassistant.EntityID) bool {
+	return r.HumidityRise != nil && r.HumidityRise.ReferenceSensor != nil && *r.HumidityRise.ReferenceSensor == entityID
+}
+
+// fmtHumidityBaseline formats the baseline of the humidity rise detection.
+func (r *Room) fmtHumidityBaseline(reference *float64) string {
+	if reference != nil {
+		return fmt.Sprintf("%.0f%% (%s)", *reference, r.HumidityRise.ReferenceSensor.FmtShort())
+	}
+
+	r.humidityRise.mu.Lock()
+	defer r.humidityRise.mu.Unlock()
+
+	return fmt.Sprintf("%.0f%%", r.humidityRise.baseline)
+}
@@ -0,0 +1,127 @@
+package automoli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/models/humidity"
+)
+
+func TestHumidityRiseTrackerUpdate(t *testing.T) {
+	type sample struct {
+		after     time.Duration
+		value     float64
+		reference *float64
+		active    bool
+		changed   bool
+	}
+
+	hysteresis := 2.0
+	reference := 50.0
+	humidReference := 70.0
+
+	tests := []struct {
+		name     string
+		settings humidity.Rise
+		samples  []sample
+	}{
+		{
+			name:     "slow rise",
+			settings: humidity.Rise{Rise: 10, Within: 10 * time.Minute},
+			samples: []sample{
+				{after: 0, value: 50},
+				{after: 8 * time.Minute, value: 55},
+				{after: 16 * time.Minute, value: 60},
+				{after: 24 * time.Minute, value: 65},
+			},
+		},
+		{
+			name:     "fast rise & release at half the rise",
+			settings: humidity.Rise{Rise: 10, Within: 10 * time.Minute},
+			samples: []sample{
+				{after: 0, value: 50},
+				{after: 2 * time.Minute, value: 56},
+				{after: 4 * time.Minute, value: 62, active: true, changed: true},
+				{after: 6 * time.Minute, value: 75, active: true},
+				{after: 20 * time.Minute, value: 56, active: true},
+				{after: 30 * time.Minute, value: 54.9, changed: true},
+			},
+		},
+		{
+			name:     "release with hysteresis",
+			settings: humidity.Rise{Rise: 10, Within: 10 * time.Minute, Hysteresis: &hysteresis},
+			samples: []sample{
+				{after: 0, value: 50},
+				{after: time.Minute, value: 60, active: true, changed: true},
+				{after: 10 * time.Minute, value: 52.5, active: true},
+				{after: 20 * time.Minute, value: 51.9, changed: true},
+			},
+		},
+		{
+			name:     "lowest sample within the window is the baseline",
+			settings: humidity.Rise{Rise: 10, Within: 10 * time.Minute},
+			samples: []sample{
+				{after: 0, value: 45},
+				{after: 5 * time.Minute, value: 52},
+				// the 45% sample is outside the window now
+				{after: 12 * time.Minute, value: 54},
+				{after: 13 * time.Minute, value: 62, active: true, changed: true},
+			},
+		},
+		{
+			name:     "reference sensor as baseline",
+			settings: humidity.Rise{Rise: 10, Within: 10 * time.Minute},
+			samples: []sample{
+				{after: 0, value: 58, reference: &reference},
+				{after: time.Hour, value: 60, reference: &reference, active: true, changed: true},
+				{after: 2 * time.Hour, value: 56, reference: &reference, active: true},
+				{after: 3 * time.Hour, value: 56, reference: &humidReference, changed: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := test.settings
+			tracker := newHumidityRiseTracker(&settings)
+			start := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+
+			for idx, sample := range test.samples {
+				active, changed := tracker.update(sample.value, start.Add(sample.after), sample.reference)
+
+				if active != sample.active || changed != sample.changed {
+					t.Fatalf("sample %d (%.1f%%): active = %t, changed = %t, want %t, %t", idx, sample.value, active, changed, sample.active, sample.changed)
+				}
+
+				if tracker.isActive() != sample.active {
+					t.Fatalf("sample %d (%.1f%%): isActive() = %t, want %t", idx, sample.value, tracker.isActive(), sample.active)
+				}
+			}
+		})
+	}
+}
+
+func TestHumidityRiseTrackerReset(t *testing.T) {
+	tracker := newHumidityRiseTracker(&humidity.Rise{Rise: 10})
+	start := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+
+	if tracker.settings.Within != defaultHumidityRiseWithin {
+		t.Errorf("within = %s, want default %s", tracker.settings.Within, defaultHumidityRiseWithin)
+	}
+
+	tracker.update(50, start, nil)
+	tracker.update(65, start.Add(time.Minute), nil)
+
+	if !tracker.reset() {
+		t.Error("reset() = false for an active shower case")
+	}
+
+	if tracker.isActive() || tracker.reset() {
+		t.Error("shower case still active after the reset")
+	}
+
+	// the samples before the reset are forgotten - no rise without a new baseline
+	if active, _ := tracker.update(65, start.Add(2*time.Minute), nil); active {
+		t.Error("shower case detected from samples before the reset")
+	}
+}
@@ -1,8 +1,10 @@
 package automoli
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -12,15 +14,26 @@ import (
 
 	"github.com/benleb/automoli-go/internal/homeassistant"
 	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/metrics"
 	"github.com/benleb/automoli-go/internal/models"
+	"github.com/benleb/automoli-go/internal/models/adaptive"
+	"github.com/benleb/automoli-go/internal/models/autodelay"
+	"github.com/benleb/automoli-go/internal/models/button"
+	"github.com/benleb/automoli-go/internal/models/condition"
 	"github.com/benleb/automoli-go/internal/models/daytime"
+	"github.com/benleb/automoli-go/internal/models/dim"
 	"github.com/benleb/automoli-go/internal/models/domain"
+	"github.com/benleb/automoli-go/internal/models/expression"
 	"github.com/benleb/automoli-go/internal/models/flash"
+	"github.com/benleb/automoli-go/internal/models/humidity"
+	"github.com/benleb/automoli-go/internal/models/notify"
 	"github.com/benleb/automoli-go/internal/models/service"
+	"github.com/benleb/automoli-go/internal/models/trigger"
 	"github.com/benleb/automoli-go/internal/style"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/go-co-op/gocron"
 	"github.com/kr/pretty"
 	"github.com/spf13/viper"
 	"golang.org/x/exp/slices"
@@ -47,30 +60,125 @@ type Room struct {
 	// // DoubleSwitchDumbLights switches on/off dumb lights (supportedFeatures: 0, e.g. switches, ...) twice to turn them on/off.
 	// DoubleSwitchDumbLights bool `json:"double_switch_dumb_lights,omitempty" mapstructure:"double_switch_dumb_lights,omitempty"`
 
+	// DisabledBy is a map of entities that control the state of this room only
+	// if any entity is in one of the given states - the room won't react to any events
+	DisabledBy map[homeassistant.EntityID][]string `json:"disabled_by,omitempty" mapstructure:"disabled_by,omitempty"`
+
+	// Conditions block turning on/off the lights while they hold, e.g. the tv is playing or a window is open
+	Conditions []*condition.Condition `json:"conditions,omitempty" mapstructure:"conditions,omitempty"`
+
 	Lights []homeassistant.EntityID `json:"lights" mapstructure:"lights"`
 
 	MotionSensors  []homeassistant.EntityID `json:"motion_sensors"             mapstructure:"motion_sensors"`
 	MotionStateOn  string                   `json:"motion_state_on,omitempty"  mapstructure:"motion_state_on,omitempty"`
 	MotionStateOff string                   `json:"motion_state_off,omitempty" mapstructure:"motion_state_off,omitempty"`
 
+	// PresenceSensors (e.g. mmWave) hold the lights on as long as they report presence - the delay starts when the last one is off
+	PresenceSensors []homeassistant.EntityID `json:"presence_sensors,omitempty" mapstructure:"presence_sensors,omitempty"`
+
+	// MotionEvents are the event types sent by the motion sensors besides state_changed (default: xiaomi_aqara.motion)
+	MotionEvents []homeassistant.EventType `json:"motion_events,omitempty" mapstructure:"motion_events,omitempty"`
+
+	// MotionTriggers are the events of single motion sensors (instead of the motion events), e.g. a zha_event matched by its device_ieee
+	MotionTriggers map[homeassistant.EntityID][]*trigger.Trigger `json:"motion_triggers,omitempty" mapstructure:"motion_triggers,omitempty"`
+
+	// Triggers are additional events treated like motion, e.g. zha_event, deconz_event, hue_event or custom events
+	Triggers []*trigger.Trigger `json:"triggers,omitempty" mapstructure:"triggers,omitempty"`
+
+	// Buttons map remote/button events to actions like locking the lights on or turning them off
+	Buttons []*button.Button `json:"buttons,omitempty" mapstructure:"buttons,omitempty"`
+
 	// sensors & threshold for humidity check
 	HumiditySensors   []homeassistant.EntityID `json:"humidity_sensors,omitempty"   mapstructure:"humidity_sensors,omitempty"`
 	HumidityThreshold *uint8                   `json:"humidity_threshold,omitempty" mapstructure:"humidity_threshold,omitempty"`
 
+	// HumidityRise detects a fast rise of the humidity instead of (or additionally to) the fixed threshold
+	HumidityRise *humidity.Rise `json:"humidity_rise,omitempty" mapstructure:"humidity_rise,omitempty"`
+	humidityRise *humidityRiseTracker
+
+	// DoorSensors mark the room as occupied if motion is detected while the doors are closed ("wasp in a box")
+	// doors in any other state than the closed state (e.g. unavailable) count as open
+	DoorSensors     []homeassistant.EntityID `json:"door_sensors,omitempty"      mapstructure:"door_sensors,omitempty"`
+	DoorStateClosed string                   `json:"door_state_closed,omitempty" mapstructure:"door_state_closed,omitempty"`
+
+	// sensors & threshold for illuminance check (the threshold can be overridden per daytime)
+	IlluminanceSensors   []homeassistant.EntityID `json:"illuminance_sensors,omitempty"   mapstructure:"illuminance_sensors,omitempty"`
+	IlluminanceThreshold *float64                 `json:"illuminance_threshold,omitempty" mapstructure:"illuminance_threshold,omitempty"`
+
+	// Dim dims the lights as a warning before they are turned off
+	Dim *dim.Dim `json:"dim,omitempty" mapstructure:"dim,omitempty"`
+
+	// NightMode overrides the active daytime while the night mode entity is on
+	NightMode       *daytime.NightMode `json:"night_mode,omitempty" mapstructure:"night_mode,omitempty"`
+	nightModeActive atomic.Bool
+
+	// MaxOnDuration turns off the lights after being on this long without motion - regardless of any locks (disabled if not set)
+	MaxOnDuration time.Duration `json:"max_on_duration,omitempty" mapstructure:"max_on_duration,omitempty"`
+	// MaxOnWarning flashes the lights this time before they are turned off after the max on duration (no warning if not set)
+	MaxOnWarning time.Duration `json:"max_on_warning,omitempty" mapstructure:"max_on_warning,omitempty"`
+
+	// Notify sends a notification if the lights are on without motion for a while
+	Notify *notify.Notify `json:"notify,omitempty" mapstructure:"notify,omitempty"`
+
+	// AutoDelay holds the bounds for delays learned from the motion history (delay: auto)
+	AutoDelay     *autodelay.Settings `json:"auto_delay,omitempty" mapstructure:"auto_delay,omitempty"`
+	motionHistory *motionHistory
+
+	// Adaptive calculates color temperature & brightness from the sun position or a curve between the daytimes
+	Adaptive *adaptive.Adaptive `json:"adaptive,omitempty" mapstructure:"adaptive,omitempty"`
+
 	// daytimes
 	Daytimes           []*daytime.Daytime `json:"daytimes" mapstructure:"daytimes"`
-	activeDaytimeIndex int
+	activeDaytimeIndex atomic.Int64
 
 	EventsChannel chan *homeassistant.EventMsg
 
 	TriggerEvents mapset.Set[homeassistant.EventType]
 
-	// turnedOnManually tracks if the lights were turned on manually or by AutoMoLi
-	// turnedOnManually bool
-
 	turnedOnByAutoMoLi bool
 
+	// manualOverride tracks if the lights were turned on or adjusted manually (detected via the context of their state changes)
+	manualOverride bool
+
+	// lockedOn keeps the lights on until they are turned off the next time (set by a button)
+	lockedOn bool
+
 	turnOffTimer *time.Timer
+	// turnOffAt is the time the turn off timer fires next
+	turnOffAt time.Time
+
+	// doorsClosedAt is the time all doors of the room were closed
+	doorsClosedAt time.Time
+	// occupied tracks if motion was detected after the doors were closed - someone is in the room
+	occupied bool
+
+	// turnedOffByTimer tracks if the lights were turned off the last time because no motion was detected
+	turnedOffByTimer bool
+
+	// lastMotion is the time of the last motion/presence/trigger event
+	lastMotion time.Time
+	// turnOnMotion is the time of the motion that turned on the lights
+	turnOnMotion time.Time
+	// maxOnWarnedAt is the time the lights flashed as a warning before being turned off after the max on duration
+	maxOnWarnedAt time.Time
+	// maxOnTimer turns off the lights after the max on warning
+	maxOnTimer *time.Timer
+	// seenOnAt is the time lights were first seen on that were already on when AutoMoLi started
+	seenOnAt time.Time
+	// forgottenNotified tracks if the forgotten lights notification was sent since the last motion
+	forgottenNotified bool
+
+	// pausedUntil is the time (unix nanoseconds) until the room is paused (no automation at all), 0 if not paused
+	pausedUntil atomic.Int64
+	// pauseTimer resumes the room after the pause
+	pauseTimer *time.Timer
+
+	// dimmed tracks if the lights are currently dimmed before being turned off
+	dimmed bool
+	// serviceDataBeforeDim holds the per-light service data to restore the lights after they were dimmed
+	serviceDataBeforeDim map[homeassistant.EntityID]map[string]interface{}
+	// turnedOnByAutoMoLiBeforeDim remembers the turnedOnByAutoMoLi flag as dimming may turn the lights (slowly) off
+	turnedOnByAutoMoLiBeforeDim bool
 
 	color lipgloss.Color
 	style lipgloss.Style
@@ -88,12 +196,7 @@ type Room struct {
 	// TODO
 	// Alias []string `json:"alias" mapstructure:"alias,omitempty"`
 	// DisableHueGroups     bool `json:"disable_hue_groups" mapstructure:"disable_hue_groups"`
-	// ThresholdIlluminance int  `json:"illuminance_threshold,omitempty" mapstructure:"illuminance_threshold,omitempty"`
 	// ThresholdHumidity    int  `json:"humidity_threshold,omitempty" mapstructure:"humidity_threshold,omitempty"`
-	// Dim       DimSettings       `json:"dim,omitempty" mapstructure:"dim,omitempty"`
-	// NightMode NightModeSettings `json:"night_mode,omitempty" mapstructure:"night_mode,omitempty"`
-	// transition to new daytime on daytime switch
-	// transitionOnDaytimeSwitch bool `mapstructure:"transition_on_daytime_switch,omitempty"`
 }
 
 func (r *Room) String() string {
@@ -108,68 +211,370 @@ func (r *Room) FmtShort() string {
 	return r.style.Render(strings.ReplaceAll(r.Name, "room", ""))
 }
 
+// blockingCondition returns the first room or active daytime condition with the given effect that currently holds.
+func (r *Room) blockingCondition(effect condition.Effect) *condition.Condition {
+	conditions := append(slices.Clone(r.Conditions), r.GetActiveDaytime().Conditions...)
+
+	env := r.expressionEnv()
+
+	for _, cond := range conditions {
+		if cond.Effect != effect {
+			continue
+		}
+
+		holds, err := cond.HoldsIn(env)
+		if err != nil {
+			r.pr.With("err", err).Warnf("evaluating condition %s failed", style.Bold(cond.String()))
+
+			continue
+		}
+
+		if holds {
+			return cond
+		}
+	}
+
+	return nil
+}
+
+// expressionEnv returns the room state & entity states for evaluating expressions.
+func (r *Room) expressionEnv() expression.Env {
+	return expression.Env{
+		GetState: r.ha.GetState,
+
+		Room:    r.Name,
+		Daytime: r.GetActiveDaytime().Name,
+
+		LightsOn: r.isLightOn(),
+		Occupied: r.isOccupied() || r.isPresenceDetected(),
+		Paused:   r.isPaused(),
+		Manual:   r.manualOverride,
+	}
+}
+
+// resolveServiceData evaluates the expressions in the service data.
+func (r *Room) resolveServiceData(serviceData map[string]interface{}) map[string]interface{} {
+	resolvedServiceData := make(map[string]interface{}, len(serviceData))
+
+	var env *expression.Env
+
+	for key, value := range serviceData {
+		valueExpression, ok := value.(*expression.Expression)
+		if !ok {
+			resolvedServiceData[key] = value
+
+			continue
+		}
+
+		// create the environment only if needed
+		if env == nil {
+			expressionEnv := r.expressionEnv()
+			env = &expressionEnv
+		}
+
+		result, err := valueExpression.Eval(*env)
+		if err != nil {
+			r.pr.With("err", err).Warnf("evaluating %s expression %s failed | skipping %s", style.Bold(key), valueExpression, style.Bold(key))
+
+			continue
+		}
+
+		resolvedServiceData[key] = result
+	}
+
+	return resolvedServiceData
+}
+
+// isDisabled checks if the room is disabled by any global or room entity.
+func (r *Room) isDisabled() bool {
+	return r.aml.isDisabled() || r.isDisabledByRoom()
+}
+
+// isDisabledByRoom checks if the room is disabled by any of its own entities.
+func (r *Room) isDisabledByRoom() bool {
+	return len(activeDisablers(r.ha, r.DisabledBy)) > 0
+}
+
+// Pause pauses the automation of the room for the given duration (a non-positive duration resumes the room).
+func (r *Room) Pause(duration time.Duration) {
+	if duration <= 0 {
+		r.Resume()
+
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.pauseTimer != nil {
+		r.pauseTimer.Stop()
+	}
+
+	r.pausedUntil.Store(time.Now().Add(duration).UnixNano())
+	r.pauseTimer = time.AfterFunc(duration, r.Resume)
+
+	r.pr.Printf("%s %s for %s %s until %s", icons.Pause, style.Bold("paused"), style.Bold(duration.String()), style.DarkDivider.String(), r.pausedUntilTime().Format("15:04:05"))
+}
+
+// Resume resumes a paused room and re-evaluates if the lights should be on or off (does nothing if the room is not paused).
+func (r *Room) Resume() {
+	r.Lock()
+	defer r.Unlock()
+
+	// not paused (anymore)
+	if r.pausedUntil.Swap(0) == 0 {
+		return
+	}
+
+	if r.pauseTimer != nil {
+		r.pauseTimer.Stop()
+	}
+
+	r.pr.Printf("%s %s", icons.Play, style.Bold("resumed"))
+
+	switch {
+	// someone is in the room - turn on the lights if the conditions are fulfilled
+	case r.isMotionDetected():
+		r.refreshTimer()
+
+		if ok, err := r.canTurnOnLights(); !ok {
+			r.pr.Infof("%s %s | %s", icons.Block, service.TurnOn.FmtStringStriketrough(), err)
+
+			metrics.TurnOnBlocked.WithLabelValues(r.Name, metrics.BlockReason(err)).Inc()
+
+			return
+		}
+
+		_ = r.turnLightsOn(time.Now())
+
+	// nobody is in the room - (re)start the timer to turn off the lights
+	case r.isLightOn():
+		r.refreshTimer()
+	}
+}
+
+// isPaused checks if the room is currently paused.
+func (r *Room) isPaused() bool {
+	return time.Now().Before(r.pausedUntilTime())
+}
+
+// pauseRemaining returns the remaining time of the current pause.
+func (r *Room) pauseRemaining() time.Duration {
+	return max(time.Until(r.pausedUntilTime()), 0)
+}
+
+// pausedUntilTime returns the end of the current pause (zero if the room is not paused).
+func (r *Room) pausedUntilTime() time.Time {
+	if pausedUntil := r.pausedUntil.Load(); pausedUntil != 0 {
+		return time.Unix(0, pausedUntil)
+	}
+
+	return time.Time{}
+}
+
+// isMotionDetected checks if any motion sensor of the room currently reports motion.
+func (r *Room) isMotionDetected() bool {
+	for _, sensor := range r.MotionSensors {
+		if entityState := r.ha.GetState(sensor); entityState != nil && entityState.State == r.motionStateOn() {
+			return true
+		}
+	}
+
+	return r.isPresenceDetected()
+}
+
+// isPresenceDetected checks if any presence sensor of the room currently reports presence.
+func (r *Room) isPresenceDetected() bool {
+	for _, sensor := range r.PresenceSensors {
+		if entityState := r.ha.GetState(sensor); entityState != nil && entityState.State == r.motionStateOn() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// motionStateOn returns the configured motion/presence state or "on".
+func (r *Room) motionStateOn() string {
+	if r.MotionStateOn == "" {
+		return "on"
+	}
+
+	return r.MotionStateOn
+}
+
+// motionStateOff returns the configured no-motion/no-presence state or "off".
+func (r *Room) motionStateOff() string {
+	if r.MotionStateOff == "" {
+		return "off"
+	}
+
+	return r.MotionStateOff
+}
+
 func (r *Room) fmtDisabler() []string {
-	// format disabling entities & states
-	disabler := make([]string, 0, len(r.aml.disabledBy()))
-	for disablingEntityID, disablingState := range r.aml.disabledBy() {
-		disabler = append(disabler, disablingEntityID.FmtString()+style.Gray(5).Render("=")+style.Bold(disablingState))
+	disabler := make([]string, 0)
+
+	// format disabling entities & states with their scope
+	for scope, disablers := range map[string]map[homeassistant.EntityID]string{"global": r.aml.disabledBy(), "room": activeDisablers(r.ha, r.DisabledBy)} {
+		for disablingEntityID, disablingState := range disablers {
+			disabler = append(disabler, style.Gray(8).Render(scope+": ")+disablingEntityID.FmtString()+style.Gray(5).Render("=")+style.Bold(disablingState))
+		}
 	}
 
+	sort.Strings(disabler)
+
 	return disabler
 }
 
+// GetActiveDaytime returns the active daytime or the night mode configuration if the night mode is active.
 func (r *Room) GetActiveDaytime() *daytime.Daytime {
-	return r.Daytimes[r.activeDaytimeIndex]
+	if r.NightMode != nil && r.nightModeActive.Load() {
+		return &r.NightMode.Daytime
+	}
+
+	return r.Daytimes[r.activeDaytimeIndex.Load()]
 }
 
 func (r *Room) GetActiveDelay() time.Duration {
-	return r.GetActiveDaytime().Delay
+	activeDaytime := r.GetActiveDaytime()
+
+	if activeDaytime.IsAutoDelay() && r.motionHistory != nil {
+		return r.motionHistory.delay(activeDaytime.Name, viper.GetDuration("automoli.defaults.delay"))
+	}
+
+	return activeDaytime.Delay
 }
 
 func (r *Room) findActiveDaytime() int {
+	if len(r.Daytimes) == 0 {
+		return -1
+	}
+
 	now := time.Now()
 
 	for _, dt := range r.Daytimes {
 		// we set the proper date (today) for the daytime start time as we only
-		// get the time itself from the config. this is necessary to compare
-		// daytime start times with the current time to conveniently check which
-		// daytime is active
-		dt.Start = time.Date(now.Year(), now.Month(), now.Day(), dt.Start.Hour(), dt.Start.Minute(), 0, 0, now.Location())
+		// get the time itself (or the sun event) from the config. this is necessary
+		// to compare daytime start times with the current time to conveniently
+		// check which daytime is active
+		start, err := dt.Start.On(now, r.aml.location)
+		if err != nil {
+			r.pr.Errorf("❌ resolving start %s of daytime %s failed | skipping it today: %+v", style.Bold(dt.Start.String()), style.Bold(dt.Name), err)
+
+			// unresolved daytimes are neither sorted nor scheduled
+			dt.Start.Time = time.Time{}
+
+			continue
+		}
+
+		dt.Start.Time = start
 	}
 
-	// sort daytimes by start time
-	sort.Slice(r.Daytimes, func(i, j int) bool {
-		return r.Daytimes[i].Start.Before(r.Daytimes[j].Start)
-	})
+	resolvedDaytimes := r.sortedDaytimes()
+	if len(resolvedDaytimes) == 0 {
+		return -1
+	}
+
+	// only daytimes configured for today (weekdays & calendars) can be active
+	todaysDaytimes := r.daytimesOn(now)
+	if len(todaysDaytimes) == 0 {
+		r.pr.Warnf("%s no daytime configured for today | using all daytimes", icons.Hae)
+
+		todaysDaytimes = resolvedDaytimes
+	}
+
+	// the last daytime of the previous day stays active until the first daytime of today starts
+	activeDaytime := todaysDaytimes[len(todaysDaytimes)-1]
+	if yesterdaysDaytimes := r.daytimesOn(now.AddDate(0, 0, -1)); len(yesterdaysDaytimes) > 0 {
+		activeDaytime = yesterdaysDaytimes[len(yesterdaysDaytimes)-1]
+	}
 
-	for idx, currentDaytime := range r.Daytimes {
+	for idx, currentDaytime := range todaysDaytimes {
 		// get next/following daytime
-		nextIdx := (idx + 1) % len(r.Daytimes)
-		nextDaytime := r.Daytimes[nextIdx]
+		nextIdx := (idx + 1) % len(todaysDaytimes)
+		nextDaytime := todaysDaytimes[nextIdx]
 
 		// this daytime start is before now
 		startBeforeNow := currentDaytime.Start.Before(now)
 		// next daytime start is after now
 		nextStartAfterNow := nextDaytime.Start.After(now)
 
-		if startBeforeNow && nextStartAfterNow {
+		if startBeforeNow && (nextStartAfterNow || nextIdx == 0) {
 			// set daytime as active if both conditions are true
-			r.activeDaytimeIndex = slices.Index(r.Daytimes, currentDaytime)
+			activeDaytime = currentDaytime
 
 			break
 		}
+	}
+
+	activeDaytimeIndex := slices.Index(r.Daytimes, activeDaytime)
+	r.activeDaytimeIndex.Store(int64(activeDaytimeIndex))
+
+	return activeDaytimeIndex
+}
+
+// sortedDaytimes returns a copy of the resolved daytimes sorted by their start time.
+// the configured daytimes are never reordered as they are read without the room lock.
+func (r *Room) sortedDaytimes() []*daytime.Daytime {
+	daytimes := slices.DeleteFunc(slices.Clone(r.Daytimes), func(dt *daytime.Daytime) bool { return !dt.Start.IsResolved() })
+
+	sort.SliceStable(daytimes, func(i, j int) bool {
+		return daytimes[i].Start.Before(daytimes[j].Start.Time)
+	})
+
+	return daytimes
+}
+
+// isDimEnabled checks if the lights should be dimmed before they are turned off.
+// dimming is skipped if the active delay is too short to fit the dim period.
+func (r *Room) isDimEnabled() bool {
+	return r.Dim.IsEnabled() && r.GetActiveDelay() > r.Dim.Before()
+}
+
+// timerDelay returns the time until the turn off timer fires - which is the active delay
+// minus the dim period if the lights should be dimmed before they are turned off.
+func (r *Room) timerDelay() time.Duration {
+	if r.isDimEnabled() {
+		return r.GetActiveDelay() - r.Dim.Before()
+	}
+
+	return r.GetActiveDelay()
+}
 
-		// set last daytime as active if no other daytime is
-		if idx == len(r.Daytimes)-1 && r.activeDaytimeIndex == 0 {
-			r.activeDaytimeIndex = slices.Index(r.Daytimes, currentDaytime)
+// daytimesOn returns the (sorted) daytimes configured for the day of the given date - without the unresolved ones.
+func (r *Room) daytimesOn(date time.Time) []*daytime.Daytime {
+	daytimes := make([]*daytime.Daytime, 0, len(r.Daytimes))
+
+	for _, dt := range r.sortedDaytimes() {
+		if r.isDaytimeOn(dt, date) {
+			daytimes = append(daytimes, dt)
 		}
 	}
 
-	return r.activeDaytimeIndex
+	return daytimes
+}
+
+// isDaytimeOn checks if the daytime is configured for the day of the given date (weekdays & calendars).
+func (r *Room) isDaytimeOn(dt *daytime.Daytime, date time.Time) bool {
+	switch {
+	case !dt.Weekdays.Contains(date.Weekday()):
+		return false
+
+	case dt.Calendar != "" && !r.aml.hasCalendarEventOn(dt.Calendar, date):
+		return false
+
+	case dt.ExceptCalendar != "" && r.aml.hasCalendarEventOn(dt.ExceptCalendar, date):
+		return false
+	}
+
+	return true
 }
 
 func (r *Room) refreshTimer() {
-	delay := r.GetActiveDelay()
+	delay := r.timerDelay()
+
+	r.turnOffAt = time.Now().Add(delay)
 
 	if r.turnOffTimer != nil {
 		r.turnOffTimer.Reset(delay)
@@ -185,17 +590,47 @@ func (r *Room) refreshTimer() {
 	}
 }
 
+// numericState returns the state of the given sensor as number.
+// sensors without a (valid) numeric state, e.g. 'unavailable' or 'unknown' ones, are reported as not ok.
+func (r *Room) numericState(sensor homeassistant.EntityID) (float64, bool) {
+	entityState := r.ha.GetState(sensor)
+	if entityState == nil {
+		return 0, false
+	}
+
+	switch entityState.State {
+	case "unavailable", "unknown", "":
+		r.pr.Debugf("%s no value from entity %s: %s", icons.Hole, sensor.FmtString(), entityState.State)
+
+		return 0, false
+	}
+
+	value, err := strconv.ParseFloat(entityState.State, 64)
+	if err != nil {
+		r.pr.Errorf("invalid value '%+v' from entity: %s", entityState.State, sensor.FmtString())
+
+		return 0, false
+	}
+
+	return value, true
+}
+
 // currentMaxHumidity finds the highest humidity value of all humidity sensors in the room.
 func (r *Room) currentMaxHumidity() (homeassistant.EntityID, uint8) {
+	currentMaxHumiditySensor, currentMax := r.maxHumidity()
+
+	return currentMaxHumiditySensor, uint8(currentMax)
+}
+
+// maxHumidity finds the highest (exact) humidity value of all humidity sensors in the room.
+func (r *Room) maxHumidity() (homeassistant.EntityID, float64) {
 	var currentMaxHumiditySensor homeassistant.EntityID
 
 	currentMax := 0.0
 
 	for _, sensor := range r.HumiditySensors {
-		currentHumidity, err := strconv.ParseFloat(r.ha.GetState(sensor).State, 64)
-		if err != nil {
-			r.pr.Errorf("%s invalid humidity value '%+v' from entity: %s", icons.Splash, r.ha.GetState(sensor).State, sensor.FmtString())
-
+		currentHumidity, ok := r.numericState(sensor)
+		if !ok {
 			continue
 		}
 
@@ -205,13 +640,18 @@ func (r *Room) currentMaxHumidity() (homeassistant.EntityID, uint8) {
 		}
 	}
 
-	r.pr.Infof("current max humidity: %+v | sensor: %+v", currentMax, currentMaxHumiditySensor.FmtString())
+	r.pr.Debugf("current max humidity: %+v | sensor: %+v", currentMax, currentMaxHumiditySensor.FmtString())
 
-	return currentMaxHumiditySensor, uint8(currentMax)
+	return currentMaxHumiditySensor, currentMax
 }
 
-// IsHumidityAboveThreshold checks if any humidity sensor in the room is above the threshold.
+// IsHumidityAboveThreshold checks if any humidity sensor in the room is above the threshold or the humidity rose fast.
 func (r *Room) IsHumidityAboveThreshold() bool {
+	// fast rise of the humidity relative to the baseline
+	if r.isHumidityRising() {
+		return true
+	}
+
 	// if no humidity sensors are configured, we won't check the humidity
 	if r.HumidityThreshold == nil {
 		return false
@@ -226,18 +666,80 @@ func (r *Room) IsHumidityAboveThreshold() bool {
 	return false
 }
 
+// currentMaxIlluminance finds the highest illuminance value of all illuminance sensors in the room.
+func (r *Room) currentMaxIlluminance() (homeassistant.EntityID, float64) {
+	var currentMaxIlluminanceSensor homeassistant.EntityID
+
+	currentMax := 0.0
+
+	for _, sensor := range r.IlluminanceSensors {
+		currentIlluminance, ok := r.numericState(sensor)
+		if !ok {
+			continue
+		}
+
+		if currentMaxIlluminanceSensor == (homeassistant.EntityID{}) || currentIlluminance > currentMax {
+			currentMax = currentIlluminance
+			currentMaxIlluminanceSensor = sensor
+		}
+	}
+
+	r.pr.Debugf("current max illuminance: %+v | sensor: %+v", currentMax, currentMaxIlluminanceSensor.ID)
+
+	return currentMaxIlluminanceSensor, currentMax
+}
+
+// activeIlluminanceThreshold returns the illuminance threshold of the active daytime or, if not set, the room.
+func (r *Room) activeIlluminanceThreshold() *float64 {
+	if threshold := r.GetActiveDaytime().IlluminanceThreshold; threshold != nil {
+		return threshold
+	}
+
+	return r.IlluminanceThreshold
+}
+
+// IsIlluminanceAboveThreshold checks if any illuminance sensor in the room is at or above the threshold.
+// sensors without a valid value are ignored - if no sensor reports a valid value, the room is not considered bright.
+func (r *Room) IsIlluminanceAboveThreshold() bool {
+	return r.illuminanceAboveThreshold() != nil
+}
+
+// illuminanceAboveThreshold returns an error with the brightest sensor if the room is bright enough (sensors are read once).
+func (r *Room) illuminanceAboveThreshold() error {
+	threshold := r.activeIlluminanceThreshold()
+
+	// if no illuminance sensors or threshold are configured, we won't check the illuminance
+	if threshold == nil || len(r.IlluminanceSensors) == 0 {
+		return nil
+	}
+
+	currentMaxIlluminanceSensor, currentMaxIlluminance := r.currentMaxIlluminance()
+	if currentMaxIlluminanceSensor == (homeassistant.EntityID{}) || currentMaxIlluminance < *threshold {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s %.0flx ≥ %.0flx", models.ErrIlluminanceAboveThreshold, currentMaxIlluminanceSensor.FmtShort(), currentMaxIlluminance, *threshold)
+}
+
 // isLightOn checks if any as light configured entity in the room is on.
 func (r *Room) isLightOn() bool {
 	lightOn := len(r.lightsOn()) > 0
 
-	// always reset turnedOnByAutoMoLi flag if we detect that the lights are off
+	// always reset turnedOnByAutoMoLi, manualOverride & lockedOn flags if we detect that the lights are off
 	if !lightOn {
 		r.turnedOnByAutoMoLi = false
+		r.manualOverride = false
+		r.lockedOn = false
 	}
 
 	return lightOn
 }
 
+// isManuallyControlled checks if the lights are on but were turned on or adjusted manually.
+func (r *Room) isManuallyControlled() bool {
+	return r.isLightOn() && (!r.turnedOnByAutoMoLi || r.manualOverride)
+}
+
 // lightsOn gets returns all lights that are currently on.
 func (r *Room) lightsOn() []homeassistant.EntityID {
 	onLights := make([]homeassistant.EntityID, 0)
@@ -281,21 +783,26 @@ func (r *Room) disabledByLightConfiguration(activeDaytime *daytime.Daytime) bool
 	return true
 }
 
-func (r *Room) turnLightsOn(triggerEvent *homeassistant.EventMsg) bool {
+func (r *Room) turnLightsOn(timeFired time.Time) bool {
 	// get the active daytime/light configuration
 	activeDaytime := r.GetActiveDaytime()
 
 	// record
-	eventToCallDuration := time.Since(triggerEvent.Event.TimeFired)
+	eventToCallDuration := time.Since(timeFired)
 
 	// turn on the lights & set state
-	turnOnResults := r.ha.TurnOn(activeDaytime.Targets, activeDaytime.ServiceData)
+	turnOnResults := r.ha.TurnOn(activeDaytime.Targets, r.applyAdaptiveValues(r.resolveServiceData(activeDaytime.ServiceData)))
 
 	// record
-	eventToLightDuration := time.Since(triggerEvent.Event.TimeFired)
+	eventToLightDuration := time.Since(timeFired)
 
-	// set turnedOnByAutoMoLi flag
+	metrics.EventToCall.WithLabelValues(r.Name, service.TurnOn.String()).Observe(eventToCallDuration.Seconds())
+	metrics.EventToLight.WithLabelValues(r.Name, service.TurnOn.String()).Observe(eventToLightDuration.Seconds())
+
+	// set turnedOnByAutoMoLi flag & reset manual override
 	r.turnedOnByAutoMoLi = true
+	r.turnedOffByTimer = false
+	r.manualOverride = false
 
 	// construct turned on message
 	turnedOnMsg := strings.Builder{}
@@ -351,20 +858,41 @@ func (r *Room) turnLightsOff(timeFired time.Time) {
 	// record
 	eventToLightDuration := time.Since(timeFired)
 
-	// reset turnedOnByAutoMoLi flag
+	metrics.EventToCall.WithLabelValues(r.Name, service.TurnOff.String()).Observe(eventToCallDuration.Seconds())
+	metrics.EventToLight.WithLabelValues(r.Name, service.TurnOff.String()).Observe(eventToLightDuration.Seconds())
+
+	// reset turnedOnByAutoMoLi, turnedOffByTimer, manualOverride & lockedOn flags
 	r.turnedOnByAutoMoLi = false
+	r.turnedOffByTimer = false
+	r.manualOverride = false
+	r.lockedOn = false
+
+	// reset dim state
+	r.dimmed = false
+	r.serviceDataBeforeDim = nil
 
 	r.lastSwitchedOff = time.Now()
+	r.turnOffAt = time.Time{}
+
+	// cancel the pending turn off after the max on warning
+	if r.maxOnTimer != nil {
+		r.maxOnTimer.Stop()
+		r.maxOnTimer = nil
+	}
+
+	r.maxOnWarnedAt = time.Time{}
 
 	// log
 	turnedOffMsg := strings.Builder{}
 	turnedOffMsg.WriteString(icons.LightOff + " ")
 	turnedOffMsg.WriteString("no motion for ")
-	turnedOffMsg.WriteString(style.Bold(activeDaytime.Delay.String()))
+	turnedOffMsg.WriteString(style.Bold(r.GetActiveDelay().String()))
 	turnedOffMsg.WriteString(" " + r.style.Faint(true).Render("→") + " ")
 	turnedOffMsg.WriteString("turned" + style.Bold(" off"))
 
 	if lightOnDuration := r.lastSwitchedOff.Sub(r.lastSwitchedOn); lightOnDuration > 0 && r.lastSwitchedOn != (time.Time{}) {
+		metrics.LightsOnSeconds.WithLabelValues(r.Name).Add(lightOnDuration.Seconds())
+
 		turnedOffMsg.WriteString(" " + style.DarkDivider.String() + " ")
 		turnedOffMsg.WriteString(style.LightGray.Render("after ") + lightOnDuration.Round(time.Second).String())
 	}
@@ -383,6 +911,9 @@ func (r *Room) offSwitcher() {
 	r.pr.Debugf("%s starting off-switcher", icons.LightOff)
 
 	for timeFired := range r.turnOffTimer.C {
+		// conditions holding the lights on, e.g. the tv is playing
+		blockingOff := r.blockingCondition(condition.BlockOff)
+
 		//
 		// turn off conditions/checks
 		switch {
@@ -392,13 +923,39 @@ func (r *Room) offSwitcher() {
 
 		// 	return
 
-		case r.aml.isDisabled():
+		case r.isDisabled():
 			// 🚫 the disabled case 🚫
 			// print disabling entities & states
 			r.pr.Printf("%s %s prevented | disabled by: %+v", icons.Block, service.TurnOff.FmtStringStriketrough(), strings.Join(r.fmtDisabler(), " | "))
 
 			continue
 
+		case r.isPaused():
+			// ⏸️ the paused case ⏸️
+			r.pr.Printf("%s %s prevented | paused for another %s", icons.Pause, service.TurnOff.FmtStringStriketrough(), r.pauseRemaining().Round(time.Second))
+
+			continue
+
+		case blockingOff != nil:
+			// 📺 the blocked by condition case 📺
+			r.pr.Printf("%s %s prevented | %s: %s", icons.Block, service.TurnOff.FmtStringStriketrough(), models.ErrBlockedByCondition, blockingOff)
+
+			continue
+
+		case r.isPresenceDetected():
+			// 🧍 the sitting still case 🧍
+			// presence sensors hold the lights on - the timer is restarted when the last one is off
+			r.pr.Printf("%s %s prevented | presence detected", icons.Motion, service.TurnOff.FmtStringStriketrough())
+
+			continue
+
+		case r.isOccupied():
+			// 🐝📦 the wasp in a box case 📦🐝
+			// motion was detected after the doors were closed - someone is still in the room
+			r.pr.Printf("%s %s prevented | occupied since %s (doors closed)", icons.Door, service.TurnOff.FmtStringStriketrough(), r.doorsClosedAt.Format("15:04:05"))
+
+			continue
+
 		case r.IsHumidityAboveThreshold():
 			// 🚿 the shower case 🚿
 			// check if someone might is taking a shower via humidity sensors
@@ -416,10 +973,16 @@ func (r *Room) offSwitcher() {
 
 			continue
 
-		case r.isLightOn() && (!r.turnedOnByAutoMoLi && r.LockState):
+		case r.lockedOn && r.isLightOn():
+			// 🔒🔘 the locked by button case 🔘🔒
+			r.pr.Printf("%s %s prevented | locked on by button", icons.Lock, service.TurnOff.FmtStringStriketrough())
+
+			continue
+
+		case r.isManuallyControlled() && r.LockState:
 			// 🔒⏼ the locked state case ⏼🔒
-			// check if the lights were turned on manually and the state is locked
-			r.pr.Printf("%s %s prevented | manually turned on & state locked", icons.Lock, service.TurnOff.FmtStringStriketrough())
+			// check if the lights were turned on/adjusted manually and the state is locked
+			r.pr.Printf("%s %s prevented | manually controlled & state locked", icons.Lock, service.TurnOff.FmtStringStriketrough())
 
 			continue
 
@@ -427,17 +990,85 @@ func (r *Room) offSwitcher() {
 			r.pr.Infof("%s %s: conditions validated", icons.LightOff, service.TurnOff.FmtString())
 		}
 
+		r.Lock()
+
+		// dim the lights as a warning and turn them off after the dim period
+		if r.isDimEnabled() && !r.dimmed && r.isLightOn() {
+			r.dimLights()
+
+			r.turnOffTimer.Reset(r.Dim.Before())
+			r.turnOffAt = time.Now().Add(r.Dim.Before())
+			r.Unlock()
+
+			continue
+		}
+
 		// turn off the lights
 		r.turnLightsOff(timeFired)
+		r.turnedOffByTimer = true
+		r.Unlock()
 	}
 }
 
-func (r *Room) FormatDaytimeConfiguration(daytime *daytime.Daytime) string {
-	activeConfiguration := strings.Builder{}
+// dimLights dims all lights that are on as a warning before they get turned off.
+func (r *Room) dimLights() {
+	dimmableLights := make([]homeassistant.EntityID, 0)
+	serviceDataBeforeDim := make(map[homeassistant.EntityID]map[string]interface{})
 
-	bright := style.Gray(8)
-	dark := style.Gray(7)
-	roomStyle := r.style.Faint(true)
+	for _, light := range r.lightsOn() {
+		// switches & plugs can't be dimmed
+		if light.Domain() != domain.Light {
+			continue
+		}
+
+		dimmableLights = append(dimmableLights, light)
+
+		// remember the current brightness to restore it if motion is detected
+		serviceDataBeforeDim[light] = make(map[string]interface{})
+		if brightness, ok := r.ha.GetState(light).Attributes.Other["brightness"].(float64); ok {
+			serviceDataBeforeDim[light]["brightness"] = brightness
+		}
+	}
+
+	if len(dimmableLights) == 0 {
+		return
+	}
+
+	r.serviceDataBeforeDim = serviceDataBeforeDim
+	r.turnedOnByAutoMoLiBeforeDim = r.turnedOnByAutoMoLi
+
+	switch r.Dim.Method {
+	case dim.Transition:
+		_ = r.ha.TurnOff(dimmableLights, map[string]interface{}{"transition": r.Dim.Before().Seconds()})
+
+	case dim.Step:
+		_ = r.ha.TurnOn(dimmableLights, map[string]interface{}{"brightness_step_pct": r.Dim.BrightnessStepPct})
+	}
+
+	r.dimmed = true
+
+	r.pr.Printf("%s %s the lights %s turning off in %s", icons.Dim, style.Bold("dimmed"), style.DarkDivider.String(), style.Bold(r.Dim.Before().String()))
+}
+
+// restoreDimmedLights restores the brightness the lights had before they were dimmed.
+func (r *Room) restoreDimmedLights() {
+	for light, serviceData := range r.serviceDataBeforeDim {
+		_ = r.ha.TurnOn([]homeassistant.EntityID{light}, serviceData)
+	}
+
+	r.turnedOnByAutoMoLi = r.turnedOnByAutoMoLiBeforeDim
+	r.dimmed = false
+	r.serviceDataBeforeDim = nil
+
+	r.pr.Printf("%s motion detected %s %s the lights", icons.LightOn, style.DarkDivider.String(), style.Bold("restored"))
+}
+
+func (r *Room) FormatDaytimeConfiguration(daytime *daytime.Daytime) string {
+	activeConfiguration := strings.Builder{}
+
+	bright := style.Gray(8)
+	dark := style.Gray(7)
+	roomStyle := r.style.Faint(true)
 
 	if daytime == r.GetActiveDaytime() {
 		bright = style.BoldStyle
@@ -459,7 +1090,7 @@ func (r *Room) FormatDaytimeConfiguration(daytime *daytime.Daytime) string {
 	case len(daytime.Targets) == 1 && daytime.Targets[0].Domain() == domain.Scene:
 		activeConfiguration.WriteString(roomStyle.Render(daytime.Targets[0].Domain().String()) + style.Gray(6).Render(".") + bright.Render(daytime.Targets[0].EntityName()))
 
-	case *daytime.BrightnessPct > 0:
+	case daytime.BrightnessPct != nil && *daytime.BrightnessPct > 0:
 		if len(daytime.Targets) > 0 {
 			for _, target := range daytime.Targets[:1] {
 				if target.Domain() == domain.Light {
@@ -501,6 +1132,14 @@ func (r *Room) GetFmtRoomConfig() string {
 	for _, currentDaytime := range r.Daytimes {
 		fmtDaytime := strings.Builder{}
 		fmtDaytime.WriteString(currentDaytime.Start.Format("15:04"))
+
+		if currentDaytime.Start.IsSunRelative() {
+			fmtDaytime.WriteString(" " + style.Gray(7).Render(currentDaytime.Start.String()))
+		}
+
+		if len(currentDaytime.Weekdays) > 0 {
+			fmtDaytime.WriteString(" " + style.Gray(7).Render(currentDaytime.Weekdays.String()))
+		}
 		// fmtDaytime.WriteString(style.DarkDivider.String())
 		// fmtDaytime.WriteString(style.LightGray.Copy().Render(daytime.Delay.String()))
 		fmtDaytime.WriteString(" " + currentDaytime.Name)
@@ -514,6 +1153,22 @@ func (r *Room) GetFmtRoomConfig() string {
 		}
 	}
 
+	// night mode
+	if r.NightMode != nil {
+		fmtNightMode := icons.Moon + " " + r.NightMode.Name + " " + r.style.Faint(true).Render("|") + " " + r.FormatDaytimeConfiguration(&r.NightMode.Daytime)
+
+		if r.nightModeActive.Load() {
+			daytimesList = append(daytimesList, listItemActive(fmtNightMode, r.color))
+		} else {
+			daytimesList = append(daytimesList, listDaytimeItem(fmtNightMode))
+		}
+	}
+
+	// adaptive lighting
+	if r.Adaptive.IsEnabled() {
+		daytimesList = append(daytimesList, listDaytimeItem(icons.Sun+" adaptive "+r.style.Faint(true).Render("|")+" "+r.Adaptive.String()))
+	}
+
 	//
 	// lights
 	lightsList := make([]string, 0)
@@ -552,6 +1207,30 @@ func (r *Room) GetFmtRoomConfig() string {
 		}
 	}
 
+	for _, sensor := range r.PresenceSensors {
+		name := fmt.Sprintf("%s | %s", r.ha.FriendlyName(sensor), sensor.FmtShort())
+
+		if entityState := r.ha.GetState(sensor); entityState != nil && entityState.State == r.motionStateOn() {
+			sensorsList = append(sensorsList, listItemMotionOn(name))
+		} else {
+			sensorsList = append(sensorsList, listItem(name))
+		}
+	}
+
+	for _, roomTrigger := range r.Triggers {
+		sensorsList = append(sensorsList, listItem(icons.Trigger+roomTrigger.String()))
+	}
+
+	for sensor, sensorTriggers := range r.MotionTriggers {
+		for _, sensorTrigger := range sensorTriggers {
+			sensorsList = append(sensorsList, listItem(icons.Trigger+sensorTrigger.String()+" → "+sensor.FmtShort()))
+		}
+	}
+
+	for _, roomButton := range r.Buttons {
+		sensorsList = append(sensorsList, listItem(icons.Button+" "+roomButton.String()))
+	}
+
 	fmtSensorsList := list.Render(
 		lipgloss.JoinHorizontal(
 			lipgloss.Top,
@@ -602,27 +1281,94 @@ func (r *Room) eventReceiver() {
 
 func (r *Room) scheduleDaytimeSwitches() {
 	for _, dt := range r.Daytimes {
-		_, err := (*r.aml.daytimeSwitcher).Every(1).Day().At(dt.Start.UTC().Format("15:04")).Tag(r.Name).Tag(dt.Name).Do(r.switchDaytime, dt)
-		if err != nil {
-			r.pr.Errorf("❌ scheduling job failed: %+v", err)
+		r.scheduleDaytimeSwitch(dt)
+	}
+
+	// sun-relative start times shift every day and need to be rescheduled daily,
+	// daytimes restricted to certain days need the active daytime to be re-evaluated
+	if slices.ContainsFunc(r.Daytimes, func(dt *daytime.Daytime) bool { return dt.Start.IsSunRelative() || dt.IsRestricted() }) {
+		r.scheduleDaytimeRefresh()
+	}
+}
+
+// scheduleDaytimeRefresh schedules the daytime refresh shortly after the next local midnight.
+// the scheduler runs in UTC, so the job is rescheduled on every refresh to follow DST changes.
+func (r *Room) scheduleDaytimeRefresh() {
+	if err := (*r.aml.daytimeSwitcher).RemoveByTags(r.Name, refreshDaytimesTag); err != nil && !errors.Is(err, gocron.ErrJobNotFoundWithTag) {
+		r.pr.Warnf("❌ removing daytime refresh failed: %+v", err)
+	}
+
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	refreshAt := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 1, 0, 0, time.Local).UTC().Format("15:04")
+
+	_, err := (*r.aml.daytimeSwitcher).Every(1).Day().At(refreshAt).Tag(r.Name).Tag(refreshDaytimesTag).Do(r.refreshDaytimes)
+	if err != nil {
+		r.pr.Errorf("❌ scheduling daytime refresh failed: %+v", err)
+	}
+}
+
+func (r *Room) scheduleDaytimeSwitch(dt *daytime.Daytime) {
+	// the start could not be resolved (e.g. no sunset at this location & date) - retried on the next daytime refresh
+	if !dt.Start.IsResolved() {
+		r.pr.Warnf("❌ not scheduling daytime %s | start %s not resolved", style.Bold(dt.Name), style.Bold(dt.Start.String()))
+
+		return
+	}
+
+	_, err := (*r.aml.daytimeSwitcher).Every(1).Day().At(dt.Start.UTC().Format("15:04")).Tag(r.Name).Tag(dt.Name).Do(r.switchDaytime, dt)
+	if err != nil {
+		r.pr.Errorf("❌ scheduling job failed: %+v", err)
+	}
+}
+
+// refreshDaytimes resolves the start times for the new day and reschedules the sun-relative daytimes.
+func (r *Room) refreshDaytimes() {
+	r.Lock()
+	r.findActiveDaytime()
+	r.Unlock()
+
+	r.scheduleDaytimeRefresh()
+
+	for _, dt := range r.Daytimes {
+		if !dt.Start.IsSunRelative() {
+			continue
 		}
+
+		if err := (*r.aml.daytimeSwitcher).RemoveByTags(r.Name, dt.Name); err != nil {
+			r.pr.Warnf("❌ removing daytime switch for %s failed: %+v", style.Bold(dt.Name), err)
+		}
+
+		r.scheduleDaytimeSwitch(dt)
+
+		r.pr.Infof("%s %s starts today at %s (%s)", icons.Alarm, style.Bold(dt.Name), style.Bold(dt.Start.Format("15:04")), dt.Start.String())
 	}
 }
 
 func (r *Room) switchDaytime(daytime *daytime.Daytime) {
 	r.pr.Debugf("%s daytime switch to: %+v", icons.Alarm, daytime)
 
+	// daytimes restricted to other days are skipped
+	if !r.isDaytimeOn(daytime, time.Now()) {
+		r.pr.Infof("%s skipping daytime %s | not configured for today", icons.Alarm, style.Bold(daytime.Name))
+
+		return
+	}
+
+	// lock the room to prevent concurrent access
+	r.Lock()
+	defer r.Unlock()
+
 	// set new active daytime
-	r.activeDaytimeIndex = slices.Index(r.Daytimes, daytime)
+	r.activeDaytimeIndex.Store(int64(slices.Index(r.Daytimes, daytime)))
 	actionDone := "set to"
 	divider := style.DarkIndicatorRight
 
-	// // optional immediate transition to new daytime
-	// if room.transitionOnDaytimeSwitch {
-	// 	actionDone = "activated"
-	// 	divider = style.DarkIndicatorRight.Copy().Foreground(room.color)
-	// 	// TODO transition to daytime
-	// }
+	// optional immediate transition to new daytime
+	transitionToDaytime := r.isTransitionOnDaytimeSwitch(daytime)
+	if transitionToDaytime {
+		actionDone = "activated"
+		divider = style.DarkIndicatorRight.Foreground(r.color)
+	}
 
 	// build daytime switch message
 	daytimeSwitchMsg := strings.Builder{}
@@ -633,6 +1379,40 @@ func (r *Room) switchDaytime(daytime *daytime.Daytime) {
 	daytimeSwitchMsg.WriteString(r.FormatDaytimeConfiguration(daytime))
 
 	r.pr.Print(daytimeSwitchMsg.String())
+
+	// apply the new daytime configuration to the lights that are already on
+	if transitionToDaytime {
+		_ = r.turnLightsOn(time.Now())
+	}
+}
+
+// isTransitionOnDaytimeSwitch checks if the configuration of the new daytime should be applied immediately.
+func (r *Room) isTransitionOnDaytimeSwitch(newDaytime *daytime.Daytime) bool {
+	switch {
+	// not enabled for the new daytime
+	case newDaytime.TransitionOnDaytimeSwitch == nil || !*newDaytime.TransitionOnDaytimeSwitch:
+		return false
+
+	// the night mode overrides the daytime configuration, dimmed lights are about to be turned off & paused rooms are left alone
+	case r.nightModeActive.Load() || r.dimmed || r.isPaused():
+		return false
+
+	// disabled by entity state or by the new daytime configuration
+	case r.isDisabled() || r.disabledByLightConfiguration(newDaytime):
+		return false
+
+	// only lights that are already on are transitioned
+	case !r.isLightOn():
+		return false
+
+	// the lights were turned on/adjusted manually and the light configuration is locked
+	case r.isManuallyControlled() && r.LockConfiguration:
+		r.pr.Infof("%s no transition to %s | manually controlled & configuration locked", icons.Lock, style.Bold(newDaytime.Name))
+
+		return false
+	}
+
+	return true
 }
 
 func (r *Room) eventHandler(event *homeassistant.EventMsg) {
@@ -644,9 +1424,68 @@ func (r *Room) eventHandler(event *homeassistant.EventMsg) {
 
 	// count events
 	r.eventsReceivedTotal.Add(1)
+	metrics.RoomEventsReceived.WithLabelValues(r.Name).Inc()
+
+	// night mode entity changed
+	if r.NightMode != nil && entityID == r.NightMode.Entity {
+		r.handleNightModeEvent(event)
+
+		return
+	}
+
+	// room light changed
+	if slices.Contains(r.Lights, entityID) {
+		r.handleLightEvent(event)
+
+		return
+	}
+
+	// humidity changed - track the humidity to detect a fast rise
+	if slices.Contains(r.HumiditySensors, entityID) || r.isHumidityReference(entityID) {
+		r.handleHumidityEvent(event)
+
+		return
+	}
+
+	// door opened/closed
+	if slices.Contains(r.DoorSensors, entityID) {
+		r.handleDoorEvent(event)
+
+		return
+	}
+
+	// button pressed
+	if roomButton := r.matchingButton(event); roomButton != nil {
+		r.handleButtonEvent(roomButton)
+
+		return
+	}
+
+	// presence sensor cleared - start the delay if nobody else is present
+	isPresenceEvent := slices.Contains(r.PresenceSensors, entityID) && eventType == homeassistant.EventStateChanged
+	if isPresenceEvent && event.Event.Data.NewState.State == r.motionStateOff() {
+		r.handlePresenceCleared(event)
+
+		return
+	}
+
+	// events matching a configured trigger are treated like motion
+	matchingTrigger := r.matchingTrigger(event)
+
+	// events matching the trigger of a motion sensor are motion of this sensor
+	if sensor, sensorTrigger := r.matchingMotionTrigger(event); matchingTrigger == nil && sensorTrigger != nil {
+		matchingTrigger = sensorTrigger
+		entityID, friendlyName = sensor, r.aml.ha.FriendlyName(sensor)
+	}
 
 	// filter out irrelevant state changes
-	if eventType == homeassistant.EventStateChanged && (r.MotionStateOn == "" || r.MotionStateOn != event.Event.Data.NewState.State) {
+	switch {
+	case isPresenceEvent && event.Event.Data.NewState.State != r.motionStateOn():
+		r.pr.Debugf("%s ignoring presence state %s | ←%s %s", icons.Blind, style.Bold(event.Event.Data.NewState.State), friendlyName, entityID.FmtShort())
+
+		return
+
+	case !isPresenceEvent && matchingTrigger == nil && eventType == homeassistant.EventStateChanged && (r.MotionStateOn == "" || r.MotionStateOn != event.Event.Data.NewState.State):
 		r.pr.Debugf("%s ignoring %s to non-trigger state %s | ←%s %s %s", icons.Blind, style.Bold(string(eventType)), style.Bold(event.Event.Data.NewState.State), friendlyName, style.DarkDivider.String(), event.Event.Data.EntityID.FmtShort())
 		r.pr.Debugf("%+v", pretty.Sprint(event.Event))
 
@@ -659,22 +1498,51 @@ func (r *Room) eventHandler(event *homeassistant.EventMsg) {
 		"%s received %s | %s%s %s %s", icons.Trigger, style.Bold(string(eventType)), style.DarkIndicatorLeft, friendlyName, style.DarkDivider.String(), entityID.FmtShort(),
 	)
 
-	// refresh the timer after valid motion event
-	r.refreshTimer()
-
 	// lock the room to prevent concurrent access
 	r.Lock()
 	defer r.Unlock()
 
+	// refresh the timer after valid motion event
+	r.refreshTimer()
+
+	// learn the gaps between motion events
+	r.recordMotionGap(time.Now())
+
+	// remember the motion for the auto delay, the max on duration & the forgotten lights notification
+	r.lastMotion = time.Now()
+	r.forgottenNotified = false
+
+	// motion behind closed doors - someone is in the room
+	r.markOccupied(event.Event.TimeFired)
+
+	// motion during the dim period restores the lights - unless the room is disabled, paused or blocked
+	if r.dimmed {
+		if err := r.automationBlocked(); err != nil {
+			r.pr.Infof("%s restoring the dimmed lights prevented | %s", icons.Block, err)
+
+			metrics.TurnOnBlocked.WithLabelValues(r.Name, metrics.BlockReason(err)).Inc()
+
+			return
+		}
+
+		r.restoreDimmedLights()
+
+		return
+	}
+
 	// check if the conditions to turn on the lights are fulfilled
 	if ok, err := r.canTurnOnLights(); !ok {
 		r.pr.Infof("%s %s | %s", icons.Block, service.TurnOn.FmtStringStriketrough(), err)
 
+		metrics.TurnOnBlocked.WithLabelValues(r.Name, metrics.BlockReason(err)).Inc()
+
 		return
 	}
 
 	// checks passed - turn on the lights 💡
-	_ = r.turnLightsOn(event)
+	if r.turnLightsOn(event.Event.TimeFired) {
+		r.turnOnMotion = r.lastMotion
+	}
 
 	// message about the trigger event
 	triggerMsg := strings.Builder{}
@@ -682,35 +1550,426 @@ func (r *Room) eventHandler(event *homeassistant.EventMsg) {
 	triggerMsg.WriteString(style.Bold(string(eventType)) + " ")
 	triggerMsg.WriteString(style.DarkDivider.String() + " ")
 	triggerMsg.WriteString(style.DarkIndicatorLeft.String())
-	triggerMsg.WriteString(friendlyName + " ")
-	triggerMsg.WriteString(event.Event.Data.EntityID.FmtShort())
+
+	if matchingTrigger != nil && entityID.ID == "" {
+		// triggers like zha_event are not related to an entity
+		triggerMsg.WriteString(matchingTrigger.String())
+	} else {
+		triggerMsg.WriteString(friendlyName + " ")
+		triggerMsg.WriteString(entityID.FmtShort())
+	}
 
 	r.pr.Info(triggerMsg.String())
 }
 
+// matchingTrigger returns the first configured trigger matching the event.
+func (r *Room) matchingTrigger(event *homeassistant.EventMsg) *trigger.Trigger {
+	for _, roomTrigger := range r.Triggers {
+		if roomTrigger.Matches(event) {
+			return roomTrigger
+		}
+	}
+
+	return nil
+}
+
+// matchingMotionTrigger returns the motion sensor & its first trigger matching the event.
+func (r *Room) matchingMotionTrigger(event *homeassistant.EventMsg) (homeassistant.EntityID, *trigger.Trigger) {
+	for sensor, sensorTriggers := range r.MotionTriggers {
+		for _, sensorTrigger := range sensorTriggers {
+			if sensorTrigger.Matches(event) {
+				return sensor, sensorTrigger
+			}
+		}
+	}
+
+	return homeassistant.EntityID{}, nil
+}
+
+// matchingButton returns the first configured button matching the event.
+func (r *Room) matchingButton(event *homeassistant.EventMsg) *button.Button {
+	for _, roomButton := range r.Buttons {
+		if roomButton.Matches(event) {
+			return roomButton
+		}
+	}
+
+	return nil
+}
+
+// matchesEvent checks if the event matches any trigger or button of the room.
+func (r *Room) matchesEvent(event *homeassistant.EventMsg) bool {
+	if _, sensorTrigger := r.matchingMotionTrigger(event); sensorTrigger != nil {
+		return true
+	}
+
+	return r.matchingTrigger(event) != nil || r.matchingButton(event) != nil
+}
+
+// handleButtonEvent runs the action of the pressed button.
+func (r *Room) handleButtonEvent(roomButton *button.Button) {
+	r.pr.Infof("%s button pressed → %s", icons.Button, style.Bold(string(roomButton.Action)))
+
+	switch roomButton.Action {
+	case button.Lock:
+		r.toggleLockedOn()
+
+	case button.Off:
+		if !r.turnOffByButton() {
+			return
+		}
+
+		// ignore motion for a while
+		r.Pause(roomButton.Duration)
+
+	case button.Cycle:
+		r.cycleDaytime()
+	}
+}
+
+// turnOffByButton turns the lights off and stops the turn off timer - returns false if no light is on.
+func (r *Room) turnOffByButton() bool {
+	r.Lock()
+	defer r.Unlock()
+
+	if !r.isLightOn() {
+		r.pr.Debugf("%s lights are off already", icons.Button)
+
+		return false
+	}
+
+	if r.turnOffTimer != nil {
+		r.turnOffTimer.Stop()
+	}
+
+	r.turnLightsOff(time.Now())
+
+	return true
+}
+
+// toggleLockedOn locks the lights on until they are turned off the next time or unlocks them.
+func (r *Room) toggleLockedOn() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.lockedOn {
+		r.lockedOn = false
+
+		// the lights will be turned off after the usual delay
+		r.refreshTimer()
+
+		r.pr.Printf("%s lights %s", icons.Lock, style.Bold("unlocked"))
+
+		return
+	}
+
+	if !r.isLightOn() && !r.isDisabledByLightConfiguration() {
+		_ = r.turnLightsOn(time.Now())
+	}
+
+	r.lockedOn = r.isLightOn()
+
+	if r.lockedOn {
+		r.pr.Printf("%s lights %s on until they are turned off", icons.Lock, style.Bold("locked"))
+	}
+}
+
+// cycleDaytime applies the configuration of the next daytime of the day until the next daytime switch.
+func (r *Room) cycleDaytime() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.nightModeActive.Load() {
+		r.pr.Infof("%s no daytime cycling | night mode active", icons.Moon)
+
+		return
+	}
+
+	daytimes := r.daytimesOn(time.Now())
+	if len(daytimes) == 0 {
+		daytimes = r.sortedDaytimes()
+	}
+
+	// start after the active daytime & skip daytimes turning off the lights
+	currentIdx := slices.Index(daytimes, r.GetActiveDaytime())
+
+	for offset := 1; offset <= len(daytimes); offset++ {
+		nextDaytime := daytimes[(currentIdx+offset)%len(daytimes)]
+
+		if r.disabledByLightConfiguration(nextDaytime) {
+			continue
+		}
+
+		r.activeDaytimeIndex.Store(int64(slices.Index(r.Daytimes, nextDaytime)))
+
+		r.refreshTimer()
+		_ = r.turnLightsOn(time.Now())
+
+		return
+	}
+
+	r.pr.Infof("%s no daytime to cycle to", icons.Shrug)
+}
+
+// areDoorsClosed checks if door sensors are configured and all of them are closed - unknown states count as open.
+func (r *Room) areDoorsClosed() bool {
+	if len(r.DoorSensors) == 0 {
+		return false
+	}
+
+	for _, sensor := range r.DoorSensors {
+		if entityState := r.ha.GetState(sensor); entityState == nil || entityState.State != r.DoorStateClosed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isOccupied checks if motion was detected after the doors were closed and they are still closed.
+func (r *Room) isOccupied() bool {
+	return r.occupied && r.areDoorsClosed()
+}
+
+// markOccupied marks the room as occupied if the motion happened while the doors are closed.
+func (r *Room) markOccupied(motionAt time.Time) {
+	if r.occupied || r.doorsClosedAt.IsZero() || !motionAt.After(r.doorsClosedAt) || !r.areDoorsClosed() {
+		return
+	}
+
+	r.occupied = true
+
+	r.pr.Printf("%s motion behind closed doors %s room %s", icons.Door, style.DarkDivider.String(), style.Bold("occupied"))
+}
+
+// handleDoorEvent tracks when the doors were closed & releases the occupancy when a door is opened.
+func (r *Room) handleDoorEvent(event *homeassistant.EventMsg) {
+	if event.Event.Type != homeassistant.EventStateChanged || event.Event.Data.OldState.State == event.Event.Data.NewState.State {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	// all doors closed - motion from now on means someone is in the room
+	if r.areDoorsClosed() {
+		r.doorsClosedAt = event.Event.TimeFired
+		r.occupied = false
+
+		r.pr.Debugf("%s doors closed", icons.Door)
+
+		return
+	}
+
+	// door opened - someone may leave the room
+	if r.occupied {
+		r.pr.Printf("%s door opened %s room %s", icons.Door, style.DarkDivider.String(), style.Bold("released"))
+	}
+
+	r.occupied = false
+	r.doorsClosedAt = time.Time{}
+
+	// (re)start the timer to turn off the lights after the usual delay if nobody moves
+	if r.isLightOn() {
+		r.refreshTimer()
+	}
+}
+
+// handlePresenceCleared starts the delay to turn off the lights when the last presence sensor reports no presence.
+func (r *Room) handlePresenceCleared(event *homeassistant.EventMsg) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.isPresenceDetected() || !r.isLightOn() {
+		return
+	}
+
+	r.pr.Infof("%s presence cleared %s %s | turning off the lights in %s", icons.Motion, style.DarkIndicatorLeft, event.Event.Data.EntityID.FmtShort(), r.timerDelay())
+
+	r.refreshTimer()
+}
+
+// handleNightModeEvent (de)activates the night mode on state changes of the night mode entity.
+func (r *Room) handleNightModeEvent(event *homeassistant.EventMsg) {
+	if event.Event.Type != homeassistant.EventStateChanged {
+		return
+	}
+
+	active := slices.Contains(r.NightMode.States, event.Event.Data.NewState.State)
+
+	// nothing changed
+	if r.nightModeActive.Swap(active) == active {
+		return
+	}
+
+	nightModeMsg := strings.Builder{}
+	nightModeMsg.WriteString(icons.Moon + " night mode ")
+
+	if active {
+		nightModeMsg.WriteString(style.Bold("activated"))
+	} else {
+		nightModeMsg.WriteString(style.Bold("deactivated") + " | daytime: " + style.Bold(r.GetActiveDaytime().Name))
+	}
+
+	nightModeMsg.WriteString(" " + style.DarkIndicatorRight.String() + " ")
+	nightModeMsg.WriteString(r.FormatDaytimeConfiguration(r.GetActiveDaytime()))
+
+	r.pr.Print(nightModeMsg.String())
+}
+
+// lightAttributes are the attributes of a light that are compared to detect manual adjustments.
+var lightAttributes = []string{"brightness", "color_mode", "color_temp_kelvin", "hs_color", "rgb_color", "xy_color", "effect"}
+
+// handleLightEvent detects manual control of the room lights by the context of their state changes.
+func (r *Room) handleLightEvent(event *homeassistant.EventMsg) {
+	if event.Event.Type != homeassistant.EventStateChanged {
+		return
+	}
+
+	data := event.Event.Data
+	origin := r.ha.Origin(data.EntityID, data.NewState.Context)
+
+	// caused by ourselves
+	if origin == homeassistant.OriginAutoMoLi {
+		r.pr.Debugf("%s ignoring own state change of %s", icons.Blind, data.EntityID.FmtShort())
+
+		return
+	}
+
+	wasOn, isOn := data.OldState.State == "on", data.NewState.State == "on"
+
+	r.Lock()
+	defer r.Unlock()
+
+	switch {
+	// manually turned on
+	case !wasOn && isOn:
+		// the room was dark before - the lights are now completely manually controlled
+		if len(r.lightsOn()) == 1 {
+			// turned back on right after we turned them off - the delay was too short
+			r.bumpAutoDelay()
+
+			r.turnedOnByAutoMoLi = false
+			r.turnedOffByTimer = false
+			r.lastSwitchedOn = time.Now()
+		}
+
+		r.manualOverride = true
+
+		// (re)start the timer to turn off manually turned on lights too
+		r.refreshTimer()
+
+		r.pr.Printf("%s %s %s manually turned %s via %s", icons.LightOn, data.EntityID.FmtShort(), style.DarkDivider.String(), style.Bold("on"), style.Bold(string(origin)))
+
+	// manually adjusted (brightness, color, scene, ...)
+	case wasOn && isOn:
+		// devices may report attribute changes on their own, only users & automations are considered
+		if origin == homeassistant.OriginPhysical || !lightAttributesChanged(data.OldState, data.NewState) {
+			return
+		}
+
+		r.manualOverride = true
+
+		r.pr.Printf("%s %s %s manually %s via %s", icons.LightOn, data.EntityID.FmtShort(), style.DarkDivider.String(), style.Bold("adjusted"), style.Bold(string(origin)))
+
+	// manually turned off
+	case wasOn && !isOn:
+		// isLightOn resets the flags if all lights are off now
+		if !r.isLightOn() {
+			if !r.lastSwitchedOn.IsZero() && r.lastSwitchedOn.After(r.lastSwitchedOff) {
+				metrics.LightsOnSeconds.WithLabelValues(r.Name).Add(time.Since(r.lastSwitchedOn).Seconds())
+			}
+
+			r.lastSwitchedOff = time.Now()
+			r.dimmed = false
+			r.serviceDataBeforeDim = nil
+		}
+
+		r.pr.Printf("%s %s %s manually turned %s via %s", icons.LightOff, data.EntityID.FmtShort(), style.DarkDivider.String(), style.Bold("off"), style.Bold(string(origin)))
+	}
+}
+
+// lightAttributesChanged checks if any of the relevant light attributes differ between the two states.
+func lightAttributesChanged(oldState, newState homeassistant.State) bool {
+	for _, attribute := range lightAttributes {
+		if !reflect.DeepEqual(oldState.Attributes.Other[attribute], newState.Attributes.Other[attribute]) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // canTurnOnLights checks if all conditions to turn on the lights are fulfilled.
 func (r *Room) canTurnOnLights() (bool, error) {
-	switch {
-	// check if the room/AutoMoLi in general is disabled
-	case r.aml.isDisabled():
-		return false, fmt.Errorf("%w: %+v", models.ErrAutoMoLiDisabled, strings.Join(r.fmtDisabler(), " | "))
+	// check if the room/AutoMoLi is disabled or paused
+	if err := r.automationDisabled(); err != nil {
+		return false, err
+	}
+
+	blockingCondition := r.blockingCondition(condition.BlockOn)
 
+	switch {
 	// check if the lights are disabled by the current daytime/light configuration
 	case r.isDisabledByLightConfiguration():
 		return false, fmt.Errorf("%w: %+v", models.ErrDaytimeDisabled, r.GetActiveDaytime())
 
 	// check if the lights are already on and were turned on by AutoMoLi
-	case r.isLightOn() && r.turnedOnByAutoMoLi:
+	case r.isLightOn() && r.turnedOnByAutoMoLi && !r.manualOverride:
 		return false, fmt.Errorf("%w: %+v", models.ErrLightAlreadyOn, r.lightsOn())
 
-	// check if the lights are already on, not turned on by AutoMoLi but the light configuration is locked
-	case r.isLightOn() && (!r.turnedOnByAutoMoLi && r.LockConfiguration):
-		return false, fmt.Errorf("%w: %+v", models.ErrLightAlreadyOn, r.lightsOn())
+	// check if the lights are already on, turned on/adjusted manually and the light configuration is locked
+	case r.isManuallyControlled() && r.LockConfiguration:
+		return false, fmt.Errorf("%w: %+v", models.ErrManualOverride, r.lightsOn())
+
+	// check if a condition blocks turning on the lights
+	case blockingCondition != nil:
+		return false, fmt.Errorf("%w: %s", models.ErrBlockedByCondition, blockingCondition)
+	}
+
+	// check if the room is already bright enough (only if the lights are off, otherwise they would brighten the room themselves)
+	if !r.isLightOn() {
+		if err := r.illuminanceAboveThreshold(); err != nil {
+			return false, err
+		}
+	}
 
 	// check if the lights were just turned on (but it may have been not recognized yet)
-	case time.Since(r.lastSwitchedOn) < viper.GetDuration("automoli.defaults.relax_after_turn_on"):
+	if time.Since(r.lastSwitchedOn) < viper.GetDuration("automoli.defaults.relax_after_turn_on") {
 		return false, fmt.Errorf("%w: %+v", models.ErrLightJustTurnedOn, time.Since(r.lastSwitchedOn))
 	}
 
 	return true, nil
 }
+
+// automationDisabled checks if AutoMoLi or the room is disabled or if the room is paused.
+func (r *Room) automationDisabled() error {
+	switch {
+	// check if the room/AutoMoLi in general is disabled
+	case r.aml.isDisabled():
+		return fmt.Errorf("%w: %+v", models.ErrAutoMoLiDisabled, strings.Join(r.fmtDisabler(), " | "))
+
+	// check if the room itself is disabled
+	case r.isDisabledByRoom():
+		return fmt.Errorf("%w: %+v", models.ErrRoomDisabled, strings.Join(r.fmtDisabler(), " | "))
+
+	// check if the room is paused
+	case r.isPaused():
+		return fmt.Errorf("%w: %s remaining", models.ErrRoomPaused, r.pauseRemaining().Round(time.Second))
+	}
+
+	return nil
+}
+
+// automationBlocked checks if the room is disabled, paused or blocked by a condition - regardless of the light state.
+func (r *Room) automationBlocked() error {
+	if err := r.automationDisabled(); err != nil {
+		return err
+	}
+
+	if blockingCondition := r.blockingCondition(condition.BlockOn); blockingCondition != nil {
+		return fmt.Errorf("%w: %s", models.ErrBlockedByCondition, blockingCondition)
+	}
+
+	return nil
+}
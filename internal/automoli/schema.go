@@ -0,0 +1,108 @@
+package automoli
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/models/adaptive"
+	"github.com/benleb/automoli-go/internal/models/button"
+	"github.com/benleb/automoli-go/internal/models/condition"
+	"github.com/benleb/automoli-go/internal/models/daytime"
+	"github.com/benleb/automoli-go/internal/models/dim"
+	"github.com/benleb/automoli-go/internal/models/flash"
+	"github.com/benleb/automoli-go/internal/models/trigger"
+	"github.com/benleb/automoli-go/internal/schema"
+)
+
+// SchemaID is the location of the published schema of the config file.
+const SchemaID = "https://raw.githubusercontent.com/benleb/automoli-go/main/automoli.schema.json"
+
+const (
+	// durationPattern matches go durations like "90s", "1h30m" or "1.5h".
+	durationPattern = `^[-+]?([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$|^0$`
+	// entityIDPattern matches home assistant entity ids like "light.kitchen".
+	entityIDPattern = `^[^.\s]+\.\S+$`
+	// startPattern matches daytime starts like "19:45" or "sunset-30m".
+	startPattern = `^(([01]?[0-9]|2[0-3]):[0-5][0-9]|[sS]un(rise|set)([+-]([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)?)$`
+	// weekdaysPattern matches weekdays like "mon", "mon-fri" or "sat,sun".
+	weekdaysPattern = `^[a-zA-Z]{2,}(-[a-zA-Z]{2,})?(\s*,\s*[a-zA-Z]{2,}(-[a-zA-Z]{2,})?)*$`
+	// targetsPattern matches ";"-separated entity ids like "light.desk; light.shelf".
+	targetsPattern = `^\s*[^.\s;]+\.[^\s;]+(\s*;\s*[^.\s;]+\.[^\s;]+)*\s*$`
+)
+
+// Schema returns the JSON Schema of the config file, derived from the config structs.
+func Schema() *schema.Schema {
+	gen := schema.NewGenerator()
+
+	minPct, maxPct := 0.0, 100.0
+
+	duration := &schema.Schema{Type: "string", Pattern: durationPattern}
+	entityID := &schema.Schema{Type: "string", Pattern: entityIDPattern}
+
+	// types decoded by hooks & text unmarshalers
+	gen.Types[reflect.TypeOf(time.Duration(0))] = duration
+	gen.Types[reflect.TypeOf(homeassistant.EntityID{})] = entityID
+	gen.Types[reflect.TypeOf(daytime.Start{})] = &schema.Schema{Type: "string", Pattern: startPattern}
+	gen.Types[reflect.TypeOf(daytime.Weekdays{})] = &schema.Schema{OneOf: []*schema.Schema{
+		{Type: "string", Pattern: weekdaysPattern},
+		{Type: "array", Items: &schema.Schema{Type: "string", Pattern: weekdaysPattern}},
+	}}
+
+	// "delay: auto" learns the delay from the motion history
+	gen.Fields["daytime.LightConfiguration.Delay"] = &schema.Schema{OneOf: []*schema.Schema{duration, {Type: "string", Enum: []interface{}{"auto"}}}}
+
+	// targets are a list or a ";"-separated string of entity ids
+	gen.Fields["daytime.Daytime.Targets"] = &schema.Schema{OneOf: []*schema.Schema{{Type: "string", Pattern: targetsPattern}, {Type: "array", Items: entityID}}}
+
+	// brightness percentages are capped at 100
+	gen.Fields["daytime.Daytime.BrightnessPct"] = &schema.Schema{Type: "integer", Minimum: &minPct, Maximum: &maxPct}
+
+	gen.Enums[reflect.TypeOf(flash.Flash(""))] = []interface{}{flash.Short, flash.Long}
+	gen.Enums[reflect.TypeOf(dim.Method(""))] = []interface{}{dim.Step, dim.Transition}
+	gen.Enums[reflect.TypeOf(adaptive.Mode(""))] = []interface{}{adaptive.Sun, adaptive.Curve}
+	gen.Enums[reflect.TypeOf(button.Action(""))] = []interface{}{button.Lock, button.Off, button.Cycle}
+	gen.Enums[reflect.TypeOf(condition.Operator(""))] = []interface{}{condition.Equal, condition.NotEqual, condition.GreaterThan, condition.LessThan, condition.In}
+	gen.Enums[reflect.TypeOf(condition.Effect(""))] = []interface{}{condition.BlockOn, condition.BlockOff}
+
+	gen.Required[reflect.TypeOf(Room{})] = []string{"name", "lights", "daytimes"}
+	gen.Required[reflect.TypeOf(daytime.Daytime{})] = []string{"name", "start"}
+	gen.Required[reflect.TypeOf(daytime.NightMode{})] = []string{"entity"}
+	gen.Required[reflect.TypeOf(trigger.Trigger{})] = []string{"event_type"}
+	gen.Required[reflect.TypeOf(button.Button{})] = []string{"event_type", "action"}
+	gen.Required[reflect.TypeOf(condition.Condition{})] = []string{"effect"}
+	gen.Required[reflect.TypeOf(HTTPConfig{})] = []string{"listen"}
+
+	// global settings & the flags that can be set in the config file too
+	config := gen.Struct(reflect.TypeOf(Config{}))
+	config.Properties["verbose"] = &schema.Schema{Type: "boolean"}
+	config.Properties["debug"] = &schema.Schema{Type: "boolean"}
+
+	rooms := &schema.Schema{Type: "array", Items: gen.Reflect(reflect.TypeOf(Room{}))}
+
+	return &schema.Schema{
+		Schema:      schema.Draft,
+		ID:          SchemaID,
+		Title:       AppName + " config",
+		Description: "configuration of " + AppName + " - fully automatic light management based on motion",
+
+		Type: "object",
+		Properties: map[string]*schema.Schema{
+			"automoli": config,
+			"homeassistant": {
+				Type: "object",
+				Properties: map[string]*schema.Schema{
+					"url":   {Type: "string", Pattern: "^https?://"},
+					"token": {Type: "string"},
+				},
+				Required:             []string{"url", "token"},
+				AdditionalProperties: false,
+			},
+			"rooms": rooms,
+		},
+		Required:             []string{"homeassistant", "rooms"},
+		AdditionalProperties: false,
+
+		Defs: gen.Defs(),
+	}
+}
@@ -0,0 +1,177 @@
+package automoli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/benleb/automoli-go/internal/models/daytime"
+	"github.com/benleb/automoli-go/internal/style"
+	"golang.org/x/exp/slices"
+)
+
+// RoomStatus is the machine-readable state of a room.
+type RoomStatus struct {
+	Name          string   `json:"name"`
+	ActiveDaytime string   `json:"active_daytime"`
+	Daytimes      []string `json:"daytimes"`
+	NightMode     bool     `json:"night_mode"`
+
+	Lights             []string `json:"lights"`
+	LightsOn           []string `json:"lights_on"`
+	TurnedOnByAutoMoLi bool     `json:"turned_on_by_automoli"`
+	ManualOverride     bool     `json:"manual_override"`
+	LockedOn           bool     `json:"locked_on"`
+	Dimmed             bool     `json:"dimmed"`
+
+	// DelaySeconds is the active delay & TurnOffInSeconds the time remaining until the turn off timer fires
+	DelaySeconds     float64  `json:"delay_seconds"`
+	TurnOffInSeconds *float64 `json:"turn_off_in_seconds,omitempty"`
+
+	Paused      bool       `json:"paused"`
+	PausedUntil *time.Time `json:"paused_until,omitempty"`
+
+	LastSwitchedOn  *time.Time `json:"last_switched_on,omitempty"`
+	LastSwitchedOff *time.Time `json:"last_switched_off,omitempty"`
+	LastMotion      *time.Time `json:"last_motion,omitempty"`
+
+	EventsReceived uint64 `json:"events_received"`
+
+	// DisabledBy are the active disablers with their scope prefix, e.g. "global:input_boolean.automoli" → "off"
+	DisabledBy map[string]string `json:"disabled_by"`
+}
+
+// Status returns the current state of the room.
+func (r *Room) Status() RoomStatus {
+	r.Lock()
+	defer r.Unlock()
+
+	status := RoomStatus{
+		Name:          r.Name,
+		ActiveDaytime: r.GetActiveDaytime().Name,
+		Daytimes:      make([]string, 0, len(r.Daytimes)),
+		NightMode:     r.nightModeActive.Load(),
+
+		Lights:             make([]string, 0, len(r.Lights)),
+		LightsOn:           make([]string, 0, len(r.Lights)),
+		TurnedOnByAutoMoLi: r.turnedOnByAutoMoLi,
+		ManualOverride:     r.manualOverride,
+		LockedOn:           r.lockedOn,
+		Dimmed:             r.dimmed,
+
+		DelaySeconds: r.GetActiveDelay().Seconds(),
+
+		Paused: r.isPaused(),
+
+		LastSwitchedOn:  timeOrNil(r.lastSwitchedOn),
+		LastSwitchedOff: timeOrNil(r.lastSwitchedOff),
+		LastMotion:      timeOrNil(r.lastMotion),
+
+		EventsReceived: r.eventsReceivedTotal.Load(),
+
+		DisabledBy: make(map[string]string),
+	}
+
+	for _, dt := range r.Daytimes {
+		status.Daytimes = append(status.Daytimes, dt.Name)
+	}
+
+	for _, light := range r.Lights {
+		status.Lights = append(status.Lights, light.ID)
+	}
+
+	for _, light := range r.lightsOn() {
+		status.LightsOn = append(status.LightsOn, light.ID)
+	}
+
+	if remaining := time.Until(r.turnOffAt); !r.turnOffAt.IsZero() && remaining > 0 && len(status.LightsOn) > 0 {
+		remainingSeconds := remaining.Seconds()
+		status.TurnOffInSeconds = &remainingSeconds
+	}
+
+	if status.Paused {
+		status.PausedUntil = timeOrNil(r.pausedUntilTime())
+	}
+
+	for entityID, state := range r.aml.disabledBy() {
+		status.DisabledBy["global:"+entityID.ID] = state
+	}
+
+	for entityID, state := range activeDisablers(r.ha, r.DisabledBy) {
+		status.DisabledBy["room:"+entityID.ID] = state
+	}
+
+	return status
+}
+
+// ForceOn turns on the lights with the active daytime configuration regardless of motion & conditions.
+func (r *Room) ForceOn() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.isDisabledByLightConfiguration() {
+		return fmt.Errorf("%w: %s", models.ErrDaytimeDisabled, r.GetActiveDaytime().Name)
+	}
+
+	r.pr.Printf("%s %s on via api", icons.LightOn, style.Bold("forced"))
+
+	_ = r.turnLightsOn(time.Now())
+
+	r.refreshTimer()
+
+	return nil
+}
+
+// ForceOff turns off the lights regardless of any locks.
+func (r *Room) ForceOff() {
+	r.Lock()
+	defer r.Unlock()
+
+	r.pr.Printf("%s %s off via api", icons.LightOff, style.Bold("forced"))
+
+	// stop the timer - the lights are turned off right now
+	if r.turnOffTimer != nil {
+		r.turnOffTimer.Stop()
+	}
+
+	r.turnLightsOff(time.Now())
+}
+
+// SetDaytime activates the daytime with the given name until the next daytime switch
+// and applies its configuration to the lights that are on.
+func (r *Room) SetDaytime(name string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.nightModeActive.Load() {
+		return models.ErrNightModeActive
+	}
+
+	idx := slices.IndexFunc(r.Daytimes, func(dt *daytime.Daytime) bool { return strings.EqualFold(dt.Name, name) })
+	if idx < 0 {
+		return fmt.Errorf("%w: %s", models.ErrUnknownDaytime, name)
+	}
+
+	r.activeDaytimeIndex.Store(int64(idx))
+
+	r.pr.Printf("%s daytime set to %s via api", icons.Alarm, style.Bold(r.Daytimes[idx].Name))
+
+	if r.isLightOn() && !r.isDisabledByLightConfiguration() {
+		r.refreshTimer()
+
+		_ = r.turnLightsOn(time.Now())
+	}
+
+	return nil
+}
+
+// timeOrNil returns nil for zero times to omit them in the json output.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
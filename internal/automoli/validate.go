@@ -0,0 +1,546 @@
+package automoli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/calendar"
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/models/adaptive"
+	"github.com/benleb/automoli-go/internal/models/condition"
+	"github.com/benleb/automoli-go/internal/models/daytime"
+	"github.com/benleb/automoli-go/internal/models/dim"
+	"github.com/benleb/automoli-go/internal/models/domain"
+	"github.com/benleb/automoli-go/internal/models/expression"
+	"github.com/benleb/automoli-go/internal/models/sun"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	// topLevelKeys are the known top-level keys of the config file.
+	topLevelKeys = []string{"automoli", "homeassistant", "rooms"}
+
+	// runtimeKeys are the keys of the automoli section that are set by flags or defaults instead of the config struct.
+	runtimeKeys = []string{"verbose", "debug", "defaults"}
+
+	// lightDomains are the domains that can be turned on/off as room lights or daytime targets.
+	lightDomains = []domain.Domain{domain.Light, domain.Switch, domain.Scene}
+	// binarySensorDomains are the domains of sensors reporting on/off states.
+	binarySensorDomains = []domain.Domain{domain.BinarySensor}
+	// sensorDomains are the domains of sensors reporting numeric states.
+	sensorDomains = []domain.Domain{domain.Sensor}
+	// motionSensorDomains are the domains of motion sensors (binary sensors or sensors sending motion events).
+	motionSensorDomains = []domain.Domain{domain.BinarySensor, domain.Sensor}
+)
+
+// ValidationReport holds the problems found in the configuration.
+type ValidationReport struct {
+	// Errors are problems that break (parts of) the configuration
+	Errors []string
+	// Warnings are problems that are probably unintended
+	Warnings []string
+
+	// references are the entities used in the configuration, checked against home assistant in live mode
+	references []entityReference
+}
+
+// entityReference is an entity used in the configuration with the domains it may have (any if empty).
+type entityReference struct {
+	path     string
+	entityID homeassistant.EntityID
+	domains  []domain.Domain
+}
+
+// OK checks if no errors were found.
+func (vr *ValidationReport) OK() bool {
+	return len(vr.Errors) == 0
+}
+
+func (vr *ValidationReport) errorf(path string, format string, args ...interface{}) {
+	vr.Errors = append(vr.Errors, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (vr *ValidationReport) warnf(path string, format string, args ...interface{}) {
+	vr.Warnings = append(vr.Warnings, path+": "+fmt.Sprintf(format, args...))
+}
+
+// decodeErrors adds every error of a (mapstructure) decoding error.
+func (vr *ValidationReport) decodeErrors(path string, err error) {
+	var decodeErr *mapstructure.Error
+	if !errors.As(err, &decodeErr) {
+		vr.errorf(path, "%v", err)
+
+		return
+	}
+
+	for _, msg := range decodeErr.Errors {
+		// "'daytimes[0]' has invalid keys: foo, bar" → "<path>.daytimes[0].foo: unknown key", "<path>.daytimes[0].bar: unknown key"
+		if rawKey, keys, found := strings.Cut(msg, "' has invalid keys: "); found && strings.HasPrefix(rawKey, "'") {
+			keyPath := path
+			if rawKey = strings.TrimPrefix(rawKey, "'"); rawKey != "" {
+				keyPath += "." + rawKey
+			}
+
+			for _, key := range strings.Split(keys, ", ") {
+				vr.errorf(keyPath+"."+key, "unknown key")
+			}
+
+			continue
+		}
+
+		// "error decoding 'daytimes[0].start': invalid daytime start" → "<path>.daytimes[0].start: invalid daytime start"
+		if rawKey, rest, found := strings.Cut(strings.TrimPrefix(msg, "error decoding '"), "': "); found && rawKey != msg {
+			vr.errorf(path+"."+rawKey, "%s", rest)
+
+			continue
+		}
+
+		vr.errorf(path, "%s", msg)
+	}
+}
+
+// addReference adds an entity to check in live mode, optionally restricted to the given domains.
+func (vr *ValidationReport) addReference(path string, entityID homeassistant.EntityID, domains ...domain.Domain) {
+	vr.references = append(vr.references, entityReference{path: path, entityID: entityID, domains: domains})
+}
+
+func (vr *ValidationReport) addReferences(path string, entityIDs []homeassistant.EntityID, domains ...domain.Domain) {
+	for _, entityID := range entityIDs {
+		vr.addReference(path, entityID, domains...)
+	}
+}
+
+// Validate checks the configuration without starting AutoMoLi. if a home assistant client
+// is given, the referenced entities are checked for existence & compatible domains too.
+func Validate(hass *homeassistant.HomeAssistant) *ValidationReport {
+	report := &ValidationReport{}
+
+	for key := range viper.AllSettings() {
+		if !slices.Contains(topLevelKeys, key) {
+			report.errorf(key, "unknown key")
+		}
+	}
+
+	if viper.GetString("homeassistant.url") == "" || viper.GetString("homeassistant.token") == "" {
+		report.errorf("homeassistant", "url & token are required")
+	}
+
+	config := report.validateGlobal()
+
+	// location for the order of sun-relative daytimes
+	location := config.Location
+	if location == nil && hass != nil {
+		if haConfig, err := hass.GetConfig(); err == nil {
+			location = &sun.Location{Latitude: haConfig.Latitude, Longitude: haConfig.Longitude}
+		}
+	}
+
+	rawRooms, ok := viper.Get("rooms").([]interface{})
+	if !ok || len(rawRooms) == 0 {
+		report.errorf("rooms", "no rooms configured")
+	}
+
+	roomNames := make(map[string]string)
+	lightRooms := make(map[homeassistant.EntityID][]string)
+
+	for idx, rawRoom := range rawRooms {
+		room := report.validateRoom(fmt.Sprintf("rooms[%d]", idx), rawRoom, config, location)
+		if room == nil {
+			continue
+		}
+
+		if previous, ok := roomNames[strings.ToLower(room.Name)]; ok {
+			report.errorf(room.Name, "duplicate room name (already used by %s)", previous)
+		}
+
+		roomNames[strings.ToLower(room.Name)] = fmt.Sprintf("rooms[%d]", idx)
+
+		for _, light := range uniqueEntities(room.Lights) {
+			lightRooms[light] = append(lightRooms[light], room.Name)
+		}
+	}
+
+	// lights controlled by multiple rooms fight over their state
+	for light, rooms := range lightRooms {
+		if len(rooms) > 1 {
+			report.warnf(light.ID, "light is used in multiple rooms: %s", strings.Join(rooms, ", "))
+		}
+	}
+
+	if hass != nil {
+		report.validateEntities(hass)
+	}
+
+	return report
+}
+
+// validateGlobal decodes the automoli section strictly and checks the calendars.
+func (vr *ValidationReport) validateGlobal() *Config {
+	config := &Config{}
+
+	var metadata mapstructure.Metadata
+
+	decoder, _ := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			daytime.StringToDelayHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			homeassistant.StringToEntityIDHookFunc(),
+		),
+		Result:   config,
+		Metadata: &metadata,
+	})
+
+	if err := decoder.Decode(viper.Get("automoli")); err != nil {
+		vr.decodeErrors("automoli", err)
+	}
+
+	for _, key := range metadata.Unused {
+		if rootKey, _, _ := strings.Cut(key, "."); !slices.Contains(runtimeKeys, rootKey) {
+			vr.errorf("automoli."+key, "unknown key")
+		}
+	}
+
+	for entityID := range config.DisabledBy {
+		vr.addReference("automoli.disabled_by", entityID)
+	}
+
+	for name, source := range config.Calendars {
+		path := "automoli.calendars." + name
+
+		// calendar entities are checked in live mode, ics files are read once
+		if strings.HasPrefix(source, domain.Calendar.String()+".") {
+			if entityID, err := homeassistant.NewEntityID(source); err != nil {
+				vr.errorf(path, "%v", err)
+			} else {
+				vr.addReference(path, *entityID, domain.Calendar)
+			}
+
+			continue
+		}
+
+		if _, err := calendar.New(source, nil); err != nil {
+			vr.errorf(path, "loading calendar %s failed: %v", source, err)
+		}
+	}
+
+	return config
+}
+
+// validateRoom decodes the room strictly and checks its settings & daytimes.
+func (vr *ValidationReport) validateRoom(path string, rawRoom interface{}, config *Config, location *sun.Location) *Room {
+	roomConfig, ok := rawRoom.(map[string]interface{})
+	if !ok {
+		vr.errorf(path, "invalid room config: %+v", rawRoom)
+
+		return nil
+	}
+
+	room := &Room{}
+
+	_, err := decodeRoom(roomConfig, room, true)
+
+	if room.Name != "" {
+		path = room.Name
+	} else {
+		vr.errorf(path, "no name configured")
+	}
+
+	// decoding errors & unknown keys
+	if err != nil {
+		vr.decodeErrors(path, err)
+	}
+
+	//
+	// entities
+
+	switch {
+	case len(room.Lights) == 0:
+		vr.errorf(path, "no lights configured")
+
+	case len(room.MotionSensors) == 0 && len(room.PresenceSensors) == 0 && len(room.Triggers) == 0:
+		vr.errorf(path, "no motion/presence sensors or triggers configured")
+	}
+
+	roomEntities := []struct {
+		key      string
+		entities []homeassistant.EntityID
+		domains  []domain.Domain
+	}{
+		{"lights", room.Lights, lightDomains},
+		{"motion_sensors", room.MotionSensors, motionSensorDomains},
+		{"presence_sensors", room.PresenceSensors, binarySensorDomains},
+		{"door_sensors", room.DoorSensors, binarySensorDomains},
+		{"humidity_sensors", room.HumiditySensors, sensorDomains},
+		{"illuminance_sensors", room.IlluminanceSensors, sensorDomains},
+	}
+
+	for _, roomEntity := range roomEntities {
+		for _, duplicate := range duplicateEntities(roomEntity.entities) {
+			vr.errorf(path+"."+roomEntity.key, "%s is configured multiple times", duplicate.ID)
+		}
+
+		vr.addReferences(path+"."+roomEntity.key, uniqueEntities(roomEntity.entities), roomEntity.domains...)
+	}
+
+	for entityID := range room.DisabledBy {
+		vr.addReference(path+".disabled_by", entityID)
+	}
+
+	if room.HumidityRise != nil && room.HumidityRise.ReferenceSensor != nil {
+		vr.addReference(path+".humidity_rise.reference_sensor", *room.HumidityRise.ReferenceSensor, sensorDomains...)
+	}
+
+	//
+	// features
+
+	for idx, roomTrigger := range room.Triggers {
+		if roomTrigger == nil || roomTrigger.EventType == "" {
+			vr.errorf(fmt.Sprintf("%s.triggers[%d]", path, idx), "no event type configured")
+		}
+	}
+
+	for sensor, sensorTriggers := range room.MotionTriggers {
+		sensorPath := path + ".motion_triggers." + sensor.ID
+
+		if !slices.Contains(room.MotionSensors, sensor) {
+			vr.errorf(sensorPath, "%s is not a motion sensor of this room", sensor.ID)
+		}
+
+		for idx, sensorTrigger := range sensorTriggers {
+			if sensorTrigger == nil || sensorTrigger.EventType == "" {
+				vr.errorf(fmt.Sprintf("%s[%d]", sensorPath, idx), "no event type configured")
+			}
+		}
+	}
+
+	for idx, roomButton := range room.Buttons {
+		if roomButton == nil || roomButton.EventType == "" || !roomButton.Action.IsValid() {
+			vr.errorf(fmt.Sprintf("%s.buttons[%d]", path, idx), "no event type or invalid action")
+		}
+	}
+
+	vr.validateConditions(path+".conditions", room.Conditions)
+
+	if room.Adaptive != nil && room.Adaptive.Mode != "" && room.Adaptive.Mode != adaptive.Sun && room.Adaptive.Mode != adaptive.Curve {
+		vr.errorf(path+".adaptive.mode", "invalid mode %s (available: %s, %s)", room.Adaptive.Mode, adaptive.Sun, adaptive.Curve)
+	}
+
+	if room.Adaptive != nil && room.Adaptive.Mode == adaptive.Sun {
+		if location == nil {
+			vr.errorf(path+".adaptive.mode", "mode %s needs a location (configure automoli.location or use --live to fetch it from home assistant)", adaptive.Sun)
+		}
+
+		if slices.ContainsFunc(room.Daytimes, setsAdaptiveValues) {
+			vr.warnf(path+".adaptive.mode", "mode %s replaces the brightness & color temperature of the daytimes", adaptive.Sun)
+		}
+	}
+
+	if room.Notify != nil {
+		if _, _, err := room.Notify.DomainService(); err != nil || !room.Notify.IsEnabled() {
+			vr.errorf(path+".notify", "invalid service %s or threshold %s", room.Notify.Service, room.Notify.After)
+		}
+	}
+
+	if room.Dim != nil && room.Dim.Method != "" && room.Dim.Method != dim.Step && room.Dim.Method != dim.Transition {
+		vr.errorf(path+".dim.method", "invalid method %s (available: %s, %s)", room.Dim.Method, dim.Step, dim.Transition)
+	}
+
+	if room.NightMode != nil {
+		if room.NightMode.Entity == (homeassistant.EntityID{}) {
+			vr.errorf(path+".night_mode", "no entity configured")
+		} else {
+			vr.addReference(path+".night_mode.entity", room.NightMode.Entity)
+		}
+
+		vr.validateDaytime(path+".night_mode", &room.NightMode.Daytime, config)
+	}
+
+	//
+	// daytimes
+
+	vr.validateDaytimes(path, room, config, location)
+
+	return room
+}
+
+// validateDaytimes checks the daytimes of the room & their order.
+func (vr *ValidationReport) validateDaytimes(path string, room *Room, config *Config, location *sun.Location) {
+	if len(room.Daytimes) == 0 {
+		vr.errorf(path, "no daytimes configured")
+
+		return
+	}
+
+	daytimeNames := make(map[string]bool)
+	today := time.Now()
+
+	var previous *daytime.Daytime
+
+	var previousStart time.Time
+
+	for idx, dt := range room.Daytimes {
+		dtPath := fmt.Sprintf("%s.daytimes[%d]", path, idx)
+
+		// not decodable - already reported
+		if dt == nil {
+			continue
+		}
+
+		if dt.Name == "" {
+			vr.errorf(dtPath, "no name configured")
+		} else {
+			dtPath = path + ".daytimes." + dt.Name
+		}
+
+		if daytimeNames[strings.ToLower(dt.Name)] {
+			vr.errorf(dtPath, "duplicate daytime name")
+		}
+
+		daytimeNames[strings.ToLower(dt.Name)] = true
+
+		vr.validateDaytime(dtPath, dt, config)
+
+		if dt.Start.IsSunRelative() && location == nil {
+			vr.errorf(dtPath+".start", "sun-relative start %s needs a location (configure automoli.location or use --live to fetch it from home assistant)", dt.Start)
+
+			continue
+		}
+
+		start, err := dt.Start.On(today, location)
+		if err != nil {
+			vr.errorf(dtPath+".start", "resolving start %s failed: %v", dt.Start, err)
+
+			continue
+		}
+
+		if previous != nil && !start.After(previousStart) {
+			switch {
+			case start.Equal(previousStart) && daytimesOverlap(previous, dt):
+				vr.errorf(dtPath+".start", "starts at the same time as daytime %s (%s)", previous.Name, start.Format("15:04"))
+
+			case start.Equal(previousStart):
+
+			case dt.Start.IsSunRelative() || previous.Start.IsSunRelative():
+				vr.warnf(dtPath+".start", "starts before daytime %s today (%s < %s) | daytimes are not sorted", previous.Name, start.Format("15:04"), previousStart.Format("15:04"))
+
+			default:
+				vr.errorf(dtPath+".start", "starts before daytime %s (%s < %s) | daytimes are not sorted", previous.Name, start.Format("15:04"), previousStart.Format("15:04"))
+			}
+		}
+
+		previous, previousStart = dt, start
+	}
+}
+
+// validateDaytime checks the settings of a daytime (or the night mode).
+func (vr *ValidationReport) validateDaytime(path string, dt *daytime.Daytime, config *Config) {
+	for _, calendarName := range []string{dt.Calendar, dt.ExceptCalendar} {
+		if _, ok := config.Calendars[calendarName]; calendarName != "" && !ok {
+			vr.errorf(path, "unknown calendar %s", calendarName)
+		}
+	}
+
+	for _, duplicate := range duplicateEntities(dt.Targets) {
+		vr.errorf(path+".target", "%s is configured multiple times", duplicate.ID)
+	}
+
+	vr.addReferences(path+".target", uniqueEntities(dt.Targets), lightDomains...)
+
+	for key, value := range dt.ServiceData {
+		if rawExpression, ok := value.(string); ok && strings.HasPrefix(rawExpression, expression.Prefix) {
+			if _, err := expression.Compile(rawExpression); err != nil {
+				vr.errorf(path+".service_data."+key, "%v", err)
+			}
+		}
+	}
+
+	vr.validateConditions(path+".conditions", dt.Conditions)
+}
+
+// validateConditions checks the conditions of a room or daytime.
+func (vr *ValidationReport) validateConditions(path string, conditions []*condition.Condition) {
+	for idx, cond := range conditions {
+		if cond == nil {
+			continue
+		}
+
+		if err := cond.Validate(); err != nil {
+			vr.errorf(fmt.Sprintf("%s[%d]", path, idx), "%v", err)
+		}
+
+		if cond.Entity.ID != "" {
+			vr.addReference(fmt.Sprintf("%s[%d]", path, idx), cond.Entity)
+		}
+	}
+}
+
+// validateEntities checks that the referenced entities exist in home assistant & have a compatible domain.
+func (vr *ValidationReport) validateEntities(hass *homeassistant.HomeAssistant) {
+	for _, ref := range vr.references {
+		rawDomain, _, _ := strings.Cut(ref.entityID.ID, ".")
+
+		if len(ref.domains) > 0 && !slices.Contains(ref.domains, domain.Domain(rawDomain)) {
+			vr.errorf(ref.path, "%s has incompatible domain %s (expected: %s)", ref.entityID.ID, rawDomain, joinDomains(ref.domains))
+		}
+
+		if hass.GetState(ref.entityID) == nil {
+			vr.errorf(ref.path, "%s not found in home assistant", ref.entityID.ID)
+		}
+	}
+}
+
+// daytimesOverlap checks if both daytimes may be active on the same day.
+func daytimesOverlap(a, b *daytime.Daytime) bool {
+	// a calendar & its exception never apply on the same day
+	if (a.Calendar != "" && a.Calendar == b.ExceptCalendar) || (b.Calendar != "" && b.Calendar == a.ExceptCalendar) {
+		return false
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if a.Weekdays.Contains(day) && b.Weekdays.Contains(day) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// duplicateEntities returns the entities configured more than once.
+func duplicateEntities(entityIDs []homeassistant.EntityID) []homeassistant.EntityID {
+	duplicates := make([]homeassistant.EntityID, 0)
+
+	for idx, entityID := range entityIDs {
+		if slices.Contains(entityIDs[:idx], entityID) && !slices.Contains(duplicates, entityID) {
+			duplicates = append(duplicates, entityID)
+		}
+	}
+
+	return duplicates
+}
+
+// uniqueEntities returns the entities without duplicates.
+func uniqueEntities(entityIDs []homeassistant.EntityID) []homeassistant.EntityID {
+	unique := make([]homeassistant.EntityID, 0, len(entityIDs))
+
+	for _, entityID := range entityIDs {
+		if !slices.Contains(unique, entityID) {
+			unique = append(unique, entityID)
+		}
+	}
+
+	return unique
+}
+
+func joinDomains(domains []domain.Domain) string {
+	names := make([]string, 0, len(domains))
+
+	for _, dom := range domains {
+		names = append(names, dom.String())
+	}
+
+	return strings.Join(names, ", ")
+}
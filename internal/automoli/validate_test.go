@@ -0,0 +1,29 @@
+package automoli
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// TestValidateExampleConfig checks that the example config in the repository root passes the validation.
+func TestValidateExampleConfig(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	viper.SetConfigFile("../../automoli.yaml")
+
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("reading example config: %v", err)
+	}
+
+	report := Validate(nil)
+
+	for _, warning := range report.Warnings {
+		t.Logf("warning: %s", warning)
+	}
+
+	if !report.OK() {
+		t.Errorf("example config is invalid: %v", report.Errors)
+	}
+}
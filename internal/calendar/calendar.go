@@ -0,0 +1,300 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/benleb/automoli-go/internal/models/domain"
+	"github.com/charmbracelet/log"
+)
+
+// Calendar tells if there is an event (holiday, vacation, ...) on a given day.
+type Calendar interface {
+	// HasEventOn checks if any event of the calendar overlaps the day of the given date.
+	HasEventOn(date time.Time) (bool, error)
+
+	// String returns the source of the calendar.
+	String() string
+}
+
+// New creates a calendar from the given source - either a calendar.* entity or the path of a local ics file.
+func New(source string, ha *homeassistant.HomeAssistant) (Calendar, error) { //nolint:ireturn
+	if strings.HasPrefix(source, domain.Calendar.String()+".") {
+		entityID, err := homeassistant.NewEntityID(source)
+		if err != nil {
+			return nil, err
+		}
+
+		return &entityCalendar{ha: ha, entityID: *entityID}, nil
+	}
+
+	calendar := &icsCalendar{path: source}
+
+	// read the file once to validate it
+	if _, err := calendar.events(); err != nil {
+		return nil, err
+	}
+
+	return calendar, nil
+}
+
+// dayBounds returns the start & end of the day of the given date.
+func dayBounds(date time.Time) (time.Time, time.Time) {
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	return dayStart, dayStart.AddDate(0, 0, 1)
+}
+
+// overlaps checks if the event from start to end overlaps the day of the given date.
+func overlaps(start, end, date time.Time) bool {
+	dayStart, dayEnd := dayBounds(date)
+
+	// events without duration are treated as a point in time
+	if !end.After(start) {
+		return !start.Before(dayStart) && start.Before(dayEnd)
+	}
+
+	return start.Before(dayEnd) && end.After(dayStart)
+}
+
+//
+// home assistant calendar entity
+
+// entityCalendar uses the state of a home assistant calendar entity. the entity
+// is on while an event is active and its attributes describe the current or next event.
+type entityCalendar struct {
+	ha       *homeassistant.HomeAssistant
+	entityID homeassistant.EntityID
+}
+
+// haTimeFormat is the format of the start_time & end_time attributes of calendar entities.
+const haTimeFormat = "2006-01-02 15:04:05"
+
+func (c *entityCalendar) HasEventOn(date time.Time) (bool, error) {
+	state := c.ha.GetState(c.entityID)
+	if state == nil {
+		return false, fmt.Errorf("%w: %s", models.ErrUnknownCalendar, c.entityID.ID)
+	}
+
+	rawStart, okStart := state.Attributes.Other["start_time"].(string)
+	rawEnd, okEnd := state.Attributes.Other["end_time"].(string)
+
+	if okStart && okEnd {
+		start, errStart := time.ParseInLocation(haTimeFormat, rawStart, date.Location())
+		end, errEnd := time.ParseInLocation(haTimeFormat, rawEnd, date.Location())
+
+		if errStart == nil && errEnd == nil {
+			return overlaps(start, end, date), nil
+		}
+	}
+
+	// no (parsable) event details - fall back to the entity state for today
+	dayStart, dayEnd := dayBounds(date)
+	isToday := !time.Now().Before(dayStart) && time.Now().Before(dayEnd)
+
+	return isToday && state.State == "on", nil
+}
+
+func (c *entityCalendar) String() string {
+	return c.entityID.ID
+}
+
+//
+// local ics file
+
+// icsCalendar reads the events from a local ics file. the file is re-read if it
+// was modified. recurring events are supported for yearly recurrences only.
+type icsCalendar struct {
+	path string
+
+	// parsed events & the modification time of the file they were parsed from
+	cachedEvents []icsEvent
+	cachedAt     time.Time
+
+	mu sync.Mutex
+}
+
+type icsEvent struct {
+	start  time.Time
+	end    time.Time
+	yearly bool
+}
+
+func (c *icsCalendar) HasEventOn(date time.Time) (bool, error) {
+	events, err := c.events()
+	if err != nil {
+		return false, err
+	}
+
+	for _, event := range events {
+		if overlaps(event.start, event.end, date) {
+			return true, nil
+		}
+
+		if !event.yearly || event.start.Year() > date.Year() {
+			continue
+		}
+
+		// check the occurrences of yearly events in the year of the date and the year before (for events spanning the new year)
+		for _, year := range []int{date.Year() - 1, date.Year()} {
+			offset := year - event.start.Year()
+			if offset >= 0 && overlaps(event.start.AddDate(offset, 0, 0), event.end.AddDate(offset, 0, 0), date) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (c *icsCalendar) String() string {
+	return c.path
+}
+
+// events returns the events of the ics file - parsed again only if the file was modified.
+func (c *icsCalendar) events() ([]icsEvent, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fileInfo, err := os.Stat(c.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cachedEvents != nil && fileInfo.ModTime().Equal(c.cachedAt) {
+		return c.cachedEvents, nil
+	}
+
+	events, err := c.parseEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	c.cachedEvents, c.cachedAt = events, fileInfo.ModTime()
+
+	return events, nil
+}
+
+// parseEvents parses all events from the ics file.
+func (c *icsCalendar) parseEvents() ([]icsEvent, error) {
+	file, err := os.Open(c.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// unfold lines - long lines are split and continued with a leading space or tab
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	events := make([]icsEvent, 0)
+
+	var (
+		event        *icsEvent
+		hasEnd       bool
+		isAllDayDate bool
+	)
+
+	for _, line := range lines {
+		rawKey, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		// properties may have parameters like "DTSTART;VALUE=DATE:20241224"
+		key, params, _ := strings.Cut(rawKey, ";")
+
+		switch {
+		case key == "BEGIN" && value == "VEVENT":
+			event, hasEnd, isAllDayDate = &icsEvent{}, false, false
+
+		case event == nil:
+			continue
+
+		case key == "DTSTART":
+			if event.start, isAllDayDate, err = parseICSDate(value, params); err != nil {
+				return nil, err
+			}
+
+		case key == "DTEND":
+			if event.end, _, err = parseICSDate(value, params); err != nil {
+				return nil, err
+			}
+
+			hasEnd = true
+
+		case key == "RRULE":
+			event.yearly = strings.Contains(value, "FREQ=YEARLY")
+
+			if !event.yearly {
+				log.Warnf("unsupported recurrence %s in %s | treated as a single event", value, c.path)
+			}
+
+		case key == "END" && value == "VEVENT":
+			// events without an end last one day (all-day events) or are just a point in time
+			if !hasEnd {
+				event.end = event.start
+
+				if isAllDayDate {
+					event.end = event.start.AddDate(0, 0, 1)
+				}
+			}
+
+			events = append(events, *event)
+			event = nil
+		}
+	}
+
+	return events, nil
+}
+
+// parseICSDate parses ics dates (all-day) & date-times (utc or local time).
+func parseICSDate(value, params string) (time.Time, bool, error) {
+	switch {
+	case strings.Contains(params, "VALUE=DATE") && !strings.Contains(params, "VALUE=DATE-TIME"), len(value) == len("20060102"):
+		date, err := time.ParseInLocation("20060102", value, time.Local)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s", models.ErrInvalidICSDate, value)
+		}
+
+		return date, true, nil
+
+	case strings.HasSuffix(value, "Z"):
+		dateTime, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s", models.ErrInvalidICSDate, value)
+		}
+
+		return dateTime.Local(), false, nil
+	}
+
+	// date-times with a TZID parameter are treated as local time
+	dateTime, err := time.ParseInLocation("20060102T150405", value, time.Local)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %s", models.ErrInvalidICSDate, value)
+	}
+
+	return dateTime, false, nil
+}
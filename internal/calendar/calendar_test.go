@@ -0,0 +1,126 @@
+package calendar
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/models"
+)
+
+// writeICS writes the given events wrapped in a calendar to a temporary ics file.
+func writeICS(t *testing.T, events ...string) string {
+	t.Helper()
+
+	content := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+
+	path := filepath.Join(t.TempDir(), "calendar.ics")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing ics file: %v", err)
+	}
+
+	return path
+}
+
+func TestParseICSDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		params   string
+		want     time.Time
+		isAllDay bool
+		err      error
+	}{
+		{"date", "20241224", "VALUE=DATE", time.Date(2024, 12, 24, 0, 0, 0, 0, time.Local), true, nil},
+		{"date without parameter", "20241224", "", time.Date(2024, 12, 24, 0, 0, 0, 0, time.Local), true, nil},
+		{"utc date-time", "20241224T183000Z", "", time.Date(2024, 12, 24, 18, 30, 0, 0, time.UTC), false, nil},
+		{"local date-time", "20241224T183000", "TZID=Europe/Berlin", time.Date(2024, 12, 24, 18, 30, 0, 0, time.Local), false, nil},
+		{"explicit date-time", "20241224T183000", "VALUE=DATE-TIME", time.Date(2024, 12, 24, 18, 30, 0, 0, time.Local), false, nil},
+		{"invalid date", "2024-12-24", "VALUE=DATE", time.Time{}, false, models.ErrInvalidICSDate},
+		{"invalid date-time", "christmas", "", time.Time{}, false, models.ErrInvalidICSDate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, isAllDay, err := parseICSDate(test.value, test.params)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error = %v, want %v", err, test.err)
+			}
+
+			if !got.Equal(test.want) || isAllDay != test.isAllDay {
+				t.Errorf("parseICSDate() = %s, %t, want %s, %t", got, isAllDay, test.want, test.isAllDay)
+			}
+		})
+	}
+}
+
+func TestICSCalendarHasEventOn(t *testing.T) {
+	path := writeICS(t,
+		// single all-day event without an end
+		"BEGIN:VEVENT\r\nSUMMARY:Day off\r\nDTSTART;VALUE=DATE:20240515\r\nEND:VEVENT\r\n",
+		// multi-day vacation, the end date is exclusive
+		"BEGIN:VEVENT\r\nSUMMARY:Vacation\r\nDTSTART;VALUE=DATE:20240805\r\nDTEND;VALUE=DATE:20240810\r\nEND:VEVENT\r\n",
+		// yearly holiday
+		"BEGIN:VEVENT\r\nSUMMARY:Christmas\r\nDTSTART;VALUE=DATE:20201225\r\nDTEND;VALUE=DATE:20201227\r\nRRULE:FREQ=YEARLY\r\nEND:VEVENT\r\n",
+		// yearly event spanning the new year, with a folded line
+		"BEGIN:VEVENT\r\nSUMMARY:New Year's\r\n  Eve\r\nDTSTART;VALUE=DATE:20201231\r\nDTEND;VALUE=DATE:20210102\r\nRRULE:FREQ=YEARLY;\r\n INTERVAL=1\r\nEND:VEVENT\r\n",
+		// unsupported recurrence is treated as a single event
+		"BEGIN:VEVENT\r\nSUMMARY:Team meeting\r\nDTSTART:20240603T090000\r\nDTEND:20240603T100000\r\nRRULE:FREQ=WEEKLY\r\nEND:VEVENT\r\n",
+	)
+
+	calendar := &icsCalendar{path: path}
+
+	tests := []struct {
+		name string
+		date time.Time
+		want bool
+	}{
+		{"single event", time.Date(2024, 5, 15, 12, 0, 0, 0, time.Local), true},
+		{"day after single event", time.Date(2024, 5, 16, 0, 0, 0, 0, time.Local), false},
+		{"first day of vacation", time.Date(2024, 8, 5, 8, 0, 0, 0, time.Local), true},
+		{"last day of vacation", time.Date(2024, 8, 9, 23, 59, 0, 0, time.Local), true},
+		{"exclusive end of vacation", time.Date(2024, 8, 10, 8, 0, 0, 0, time.Local), false},
+		{"yearly event in its first year", time.Date(2020, 12, 26, 12, 0, 0, 0, time.Local), true},
+		{"yearly event years later", time.Date(2024, 12, 25, 12, 0, 0, 0, time.Local), true},
+		{"day after yearly event", time.Date(2024, 12, 27, 12, 0, 0, 0, time.Local), false},
+		{"yearly event before its first year", time.Date(2019, 12, 25, 12, 0, 0, 0, time.Local), false},
+		{"yearly event spanning the new year", time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local), true},
+		{"weekly event", time.Date(2024, 6, 3, 12, 0, 0, 0, time.Local), true},
+		{"weekly event a week later", time.Date(2024, 6, 10, 12, 0, 0, 0, time.Local), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := calendar.HasEventOn(test.date)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("HasEventOn(%s) = %t, want %t", test.date.Format(time.DateOnly), got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewICSCalendarErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		err  error
+	}{
+		{"missing file", filepath.Join(t.TempDir(), "missing.ics"), os.ErrNotExist},
+		{"invalid date", writeICS(t, "BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:2024-05-15\r\nEND:VEVENT\r\n"), models.ErrInvalidICSDate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := New(test.path, nil); !errors.Is(err, test.err) {
+				t.Errorf("error = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
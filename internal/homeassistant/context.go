@@ -0,0 +1,210 @@
+package homeassistant
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Origin describes who or what caused a state change.
+type Origin string
+
+const (
+	// OriginAutoMoLi are state changes caused by our own service calls.
+	OriginAutoMoLi Origin = "automoli"
+	// OriginUI are state changes caused by a user, e.g. via the Home Assistant UI or app.
+	OriginUI Origin = "ui"
+	// OriginAutomation are state changes caused by automations, scripts or other triggered actions.
+	OriginAutomation Origin = "automation"
+	// OriginPhysical are state changes reported by the device itself, e.g. a physical switch.
+	OriginPhysical Origin = "physical"
+)
+
+var (
+	// ownContextRetention is the time we remember the contexts of our own service calls.
+	ownContextRetention = time.Hour
+
+	// ownCallGrace is the time after a service call in which state changes without a user are
+	// still attributed to the call (devices reporting their new state after a command).
+	// same as the time Home Assistant keeps the context of an entity after a service call.
+	ownCallGrace = 5 * time.Second
+)
+
+// ownCalls keeps track of our own service calls to recognize the state changes they cause.
+type ownCalls struct {
+	// contexts of our own service calls
+	contexts map[string]time.Time
+	// number of calls in flight per entity
+	pending map[EntityID]int
+	// time of the last call per entity
+	lastCall map[EntityID]time.Time
+	// entities switched by calls to scenes & groups
+	covers map[EntityID][]EntityID
+	// user of our own service calls (the user of the access token)
+	userID string
+
+	mu sync.Mutex
+}
+
+func newOwnCalls() *ownCalls {
+	return &ownCalls{
+		contexts: make(map[string]time.Time),
+		pending:  make(map[EntityID]int),
+		lastCall: make(map[EntityID]time.Time),
+		covers:   make(map[EntityID][]EntityID),
+	}
+}
+
+// cover registers the entities switched by calls to the target, e.g. the lights of a scene.
+func (oc *ownCalls) cover(target EntityID, entities []EntityID) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	for _, entityID := range entities {
+		if entityID != target && !slices.Contains(oc.covers[target], entityID) {
+			oc.covers[target] = append(oc.covers[target], entityID)
+		}
+	}
+}
+
+// affected returns the target and all entities switched by calls to it.
+func (oc *ownCalls) affected(target EntityID, members []EntityID) []EntityID {
+	entities := append([]EntityID{target}, oc.covers[target]...)
+
+	for _, member := range members {
+		if !slices.Contains(entities, member) {
+			entities = append(entities, member)
+		}
+	}
+
+	return entities
+}
+
+// start marks a call for the target (and the entities switched by it) as in flight.
+func (oc *ownCalls) start(target EntityID, members []EntityID) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	for _, entityID := range oc.affected(target, members) {
+		oc.pending[entityID]++
+		oc.lastCall[entityID] = time.Now()
+	}
+}
+
+// done marks a call for the target (and the entities switched by it) as finished and remembers the context of its result.
+func (oc *ownCalls) done(target EntityID, members []EntityID, ctx stateContext) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	for _, entityID := range oc.affected(target, members) {
+		if oc.pending[entityID]--; oc.pending[entityID] <= 0 {
+			delete(oc.pending, entityID)
+		}
+
+		oc.lastCall[entityID] = time.Now()
+	}
+
+	if ctx.UserID != "" {
+		oc.userID = ctx.UserID
+	}
+
+	if ctx.ID == "" {
+		return
+	}
+
+	// forget old contexts
+	for id, added := range oc.contexts {
+		if time.Since(added) > ownContextRetention {
+			delete(oc.contexts, id)
+		}
+	}
+
+	oc.contexts[ctx.ID] = time.Now()
+}
+
+// origin classifies the state change of the entity with the given context.
+func (oc *ownCalls) origin(entityID EntityID, ctx stateContext) Origin {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	_, ownContext := oc.contexts[ctx.ID]
+	_, ownParentContext := oc.contexts[ctx.ParentID]
+
+	switch {
+	// caused by one of our calls (directly or e.g. via a scene we activated)
+	case ownContext || (ctx.ParentID != "" && ownParentContext):
+		return OriginAutoMoLi
+
+	// our call is still in flight - its context is not known yet
+	case oc.pending[entityID] > 0:
+		return OriginAutoMoLi
+
+	// device reporting its new state shortly after our call (without a user or with the user of our calls)
+	case (ctx.UserID == "" || ctx.UserID == oc.userID) && ctx.ParentID == "" && time.Since(oc.lastCall[entityID]) < ownCallGrace:
+		return OriginAutoMoLi
+
+	case ctx.ParentID != "":
+		return OriginAutomation
+
+	case ctx.UserID != "":
+		return OriginUI
+	}
+
+	return OriginPhysical
+}
+
+// Covers registers the entities switched by calls to the target, e.g. the lights activated by a scene,
+// to recognize their state changes as caused by our calls too.
+func (ha *HomeAssistant) Covers(target EntityID, entities []EntityID) {
+	ha.ownCalls.cover(target, entities)
+}
+
+// members returns the entities of a scene or group known from its state.
+func (ha *HomeAssistant) members(target EntityID) []EntityID {
+	ha.statesMu.RLock()
+	state, ok := ha.states[target]
+	ha.statesMu.RUnlock()
+
+	if !ok || state == nil {
+		return nil
+	}
+
+	rawMembers, _ := state.Attributes.Other["entity_id"].([]interface{})
+	members := make([]EntityID, 0, len(rawMembers))
+
+	for _, rawMember := range rawMembers {
+		if member, err := NewEntityID(fmt.Sprint(rawMember)); err == nil {
+			members = append(members, *member)
+		}
+	}
+
+	return members
+}
+
+// Origin classifies who or what caused the state change of the entity with the given context.
+func (ha *HomeAssistant) Origin(entityID EntityID, ctx stateContext) Origin {
+	return ha.ownCalls.origin(entityID, ctx)
+}
+
+// Context returns the context of the result, e.g. of a service call.
+func (m *ResultMsg) Context() (stateContext, bool) {
+	result, ok := m.Result.(map[string]interface{})
+	if !ok {
+		return stateContext{}, false
+	}
+
+	rawContext, ok := result["context"].(map[string]interface{})
+	if !ok {
+		return stateContext{}, false
+	}
+
+	var ctx stateContext
+
+	ctx.ID, _ = rawContext["id"].(string)
+	ctx.ParentID, _ = rawContext["parent_id"].(string)
+	ctx.UserID, _ = rawContext["user_id"].(string)
+
+	return ctx, ctx.ID != ""
+}
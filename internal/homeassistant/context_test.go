@@ -0,0 +1,173 @@
+package homeassistant
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOwnCallsOrigin(t *testing.T) {
+	var (
+		light    = EntityID{ID: "light.kitchen"}
+		spot     = EntityID{ID: "light.kitchen_spot"}
+		scene    = EntityID{ID: "scene.kitchen_bright"}
+		group    = EntityID{ID: "light.kitchen_group"}
+		member   = EntityID{ID: "light.kitchen_ceiling"}
+		stranger = EntityID{ID: "light.hallway"}
+
+		ownUser   = "automoli-user"
+		otherUser = "someone-else"
+	)
+
+	tests := []struct {
+		name string
+		// prepare records the calls made before the state change
+		prepare  func(oc *ownCalls)
+		entityID EntityID
+		ctx      stateContext
+		want     Origin
+	}{
+		{
+			name:     "context of our call",
+			prepare:  func(oc *ownCalls) { oc.done(light, nil, stateContext{ID: "own", UserID: ownUser}) },
+			entityID: light,
+			ctx:      stateContext{ID: "own", UserID: ownUser},
+			want:     OriginAutoMoLi,
+		},
+		{
+			name: "child context of our scene call",
+			prepare: func(oc *ownCalls) {
+				oc.start(scene, nil)
+				oc.done(scene, nil, stateContext{ID: "scene"})
+			},
+			entityID: stranger,
+			ctx:      stateContext{ID: "child", ParentID: "scene"},
+			want:     OriginAutoMoLi,
+		},
+		{
+			name:     "call still in flight",
+			prepare:  func(oc *ownCalls) { oc.start(light, nil) },
+			entityID: light,
+			ctx:      stateContext{ID: "unknown"},
+			want:     OriginAutoMoLi,
+		},
+		{
+			name:     "group member while the call is in flight",
+			prepare:  func(oc *ownCalls) { oc.start(group, []EntityID{member}) },
+			entityID: member,
+			ctx:      stateContext{ID: "unknown"},
+			want:     OriginAutoMoLi,
+		},
+		{
+			name: "covered light of a scene within the grace period",
+			prepare: func(oc *ownCalls) {
+				oc.cover(scene, []EntityID{light, spot})
+				oc.start(scene, nil)
+				oc.done(scene, nil, stateContext{ID: "scene"})
+			},
+			entityID: spot,
+			ctx:      stateContext{ID: "device-report"},
+			want:     OriginAutoMoLi,
+		},
+		{
+			name: "device report with our user within the grace period",
+			prepare: func(oc *ownCalls) {
+				oc.start(light, nil)
+				oc.done(light, nil, stateContext{ID: "own", UserID: ownUser})
+			},
+			entityID: light,
+			ctx:      stateContext{ID: "device-report", UserID: ownUser},
+			want:     OriginAutoMoLi,
+		},
+		{
+			name: "other user within the grace period",
+			prepare: func(oc *ownCalls) {
+				oc.start(light, nil)
+				oc.done(light, nil, stateContext{ID: "own", UserID: ownUser})
+			},
+			entityID: light,
+			ctx:      stateContext{ID: "ui", UserID: otherUser},
+			want:     OriginUI,
+		},
+		{
+			name: "automation within the grace period",
+			prepare: func(oc *ownCalls) {
+				oc.start(light, nil)
+				oc.done(light, nil, stateContext{ID: "own"})
+			},
+			entityID: light,
+			ctx:      stateContext{ID: "automation", ParentID: "trigger"},
+			want:     OriginAutomation,
+		},
+		{
+			name: "device report after the grace period",
+			prepare: func(oc *ownCalls) {
+				oc.start(light, nil)
+				oc.done(light, nil, stateContext{ID: "own"})
+				oc.lastCall[light] = time.Now().Add(-2 * ownCallGrace)
+			},
+			entityID: light,
+			ctx:      stateContext{ID: "device-report"},
+			want:     OriginPhysical,
+		},
+		{
+			name:     "user without our calls",
+			prepare:  func(*ownCalls) {},
+			entityID: light,
+			ctx:      stateContext{ID: "ui", UserID: otherUser},
+			want:     OriginUI,
+		},
+		{
+			name:     "automation without our calls",
+			prepare:  func(*ownCalls) {},
+			entityID: light,
+			ctx:      stateContext{ID: "automation", ParentID: "trigger"},
+			want:     OriginAutomation,
+		},
+		{
+			name:     "physical switch",
+			prepare:  func(*ownCalls) {},
+			entityID: light,
+			ctx:      stateContext{ID: "device-report"},
+			want:     OriginPhysical,
+		},
+		{
+			name: "other entity after our call",
+			prepare: func(oc *ownCalls) {
+				oc.start(light, nil)
+				oc.done(light, nil, stateContext{ID: "own"})
+			},
+			entityID: stranger,
+			ctx:      stateContext{ID: "device-report"},
+			want:     OriginPhysical,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := newOwnCalls()
+			test.prepare(calls)
+
+			if got := calls.origin(test.entityID, test.ctx); got != test.want {
+				t.Errorf("origin(%s, %+v) = %s, want %s", test.entityID.ID, test.ctx, got, test.want)
+			}
+		})
+	}
+}
+
+func TestOwnCallsDoneForgetsOldContexts(t *testing.T) {
+	calls := newOwnCalls()
+	light := EntityID{ID: "light.kitchen"}
+
+	calls.done(light, nil, stateContext{ID: "old"})
+	calls.contexts["old"] = time.Now().Add(-2 * ownContextRetention)
+
+	calls.done(light, nil, stateContext{ID: "new"})
+
+	if _, ok := calls.contexts["old"]; ok {
+		t.Error("context older than the retention is still remembered")
+	}
+
+	if _, ok := calls.contexts["new"]; !ok {
+		t.Error("context of the latest call is not remembered")
+	}
+}
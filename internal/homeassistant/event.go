@@ -9,6 +9,9 @@ var (
 	EventXiaomiMotion         = EventType("xiaomi_aqara.motion")
 	EventHomeAssistantStart   = EventType("homeassistant_start")
 	EventHomeAssistantStarted = EventType("homeassistant_started")
+
+	// EventAutoMoLiPause pauses a room for a duration, e.g. {"room": "bedroom", "duration": "2h"}.
+	EventAutoMoLiPause = EventType("automoli_pause")
 )
 
 type EventType string
@@ -25,6 +28,9 @@ type eventData struct {
 	EntityID EntityID `json:"entity_id" mapstructure:"entity_id"`
 	NewState State    `json:"new_state" mapstructure:"new_state"`
 	OldState State    `json:"old_state" mapstructure:"old_state"`
+
+	// Other holds the data of non-state_changed events
+	Other map[string]interface{} `json:"-" mapstructure:",remain"`
 }
 
 type State struct {
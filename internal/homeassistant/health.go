@@ -0,0 +1,87 @@
+package homeassistant
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+)
+
+// livenessMaxAgeFactor is the multiple of the watchdog max age without any message after which
+// the connection is considered dead - the watchdog failed to reconnect in the meantime.
+const livenessMaxAgeFactor = 3
+
+// Health is the state of the connection to Home Assistant.
+type Health struct {
+	Connected     bool `json:"connected"`
+	Authenticated bool `json:"authenticated"`
+
+	// Subscriptions are the desired & ActiveSubscriptions the actually active subscriptions
+	Subscriptions       []string `json:"subscriptions"`
+	ActiveSubscriptions []string `json:"active_subscriptions"`
+
+	// States is the number of entities in the states cache
+	States int `json:"states"`
+
+	LastMessage             time.Time `json:"last_message"`
+	SinceLastMessageSeconds float64   `json:"since_last_message_seconds"`
+	WatchdogMaxAgeSeconds   float64   `json:"watchdog_max_age_seconds"`
+}
+
+// Health returns the state of the connection to Home Assistant.
+func (ha *HomeAssistant) Health() Health {
+	ha.statesMu.RLock()
+	numStates := len(ha.states)
+	ha.statesMu.RUnlock()
+
+	lastMessage := ha.lastEventReceivedAt()
+
+	return Health{
+		Connected:     ha.conn.Load() != nil,
+		Authenticated: ha.authenticated.Load(),
+
+		Subscriptions:       eventTypeStrings(ha.subscriptions.ToSlice()),
+		ActiveSubscriptions: eventTypeStrings(ha.activeSubscriptions.ToSlice()),
+
+		States: numStates,
+
+		LastMessage:             lastMessage,
+		SinceLastMessageSeconds: time.Since(lastMessage).Seconds(),
+		WatchdogMaxAgeSeconds:   viper.GetDuration("homeassistant.defaults.watchdog_max_age").Seconds(),
+	}
+}
+
+// IsAlive checks if messages are still received - or at least were received recently enough for the watchdog to reconnect.
+func (h Health) IsAlive() bool {
+	return h.WatchdogMaxAgeSeconds <= 0 || h.SinceLastMessageSeconds < livenessMaxAgeFactor*h.WatchdogMaxAgeSeconds
+}
+
+// IsReady checks if the connection is authenticated, the states are fetched and all desired subscriptions are active.
+func (h Health) IsReady() bool {
+	return h.Connected && h.Authenticated && h.States > 0 && len(h.MissingSubscriptions()) == 0 && h.IsAlive()
+}
+
+// MissingSubscriptions returns the desired subscriptions that are not active.
+func (h Health) MissingSubscriptions() []string {
+	missing := make([]string, 0)
+
+	for _, subscription := range h.Subscriptions {
+		if !slices.Contains(h.ActiveSubscriptions, subscription) {
+			missing = append(missing, subscription)
+		}
+	}
+
+	return missing
+}
+
+func eventTypeStrings(eventTypes []EventType) []string {
+	strs := make([]string, 0, len(eventTypes))
+
+	for _, eventType := range eventTypes {
+		strs = append(strs, string(eventType))
+	}
+
+	slices.Sort(strs)
+
+	return strs
+}
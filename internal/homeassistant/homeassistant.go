@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/benleb/automoli-go/internal/icons"
+	"github.com/benleb/automoli-go/internal/metrics"
 	"github.com/benleb/automoli-go/internal/models"
 	"github.com/benleb/automoli-go/internal/models/domain"
 	"github.com/benleb/automoli-go/internal/models/service"
@@ -32,7 +33,7 @@ var (
 	// allowedServiceData contains the allowed keys for service_data per service and domain.
 	allowedServiceData = map[service.Service]map[domain.Domain]mapset.Set[string]{
 		service.TurnOn: {
-			domain.Light:  mapset.NewSet[string]("transition", "rgb_color", "rgbw_color", "rgbww_color", "color_name", "hs_color", "xy_color", "color_temp", "kelvin", "brightness", "brightness_pct", "brightness_step", "brightness_step_pct", "white", "profile", "flash", "effect"),
+			domain.Light:  mapset.NewSet[string]("transition", "rgb_color", "rgbw_color", "rgbww_color", "color_name", "hs_color", "xy_color", "color_temp", "color_temp_kelvin", "kelvin", "brightness", "brightness_pct", "brightness_step", "brightness_step_pct", "white", "profile", "flash", "effect"),
 			domain.Scene:  mapset.NewSet[string]("transition"),
 			domain.Switch: mapset.NewSet[string](),
 		},
@@ -41,12 +42,21 @@ var (
 			domain.Switch: mapset.NewSet[string](),
 		},
 		service.Toggle: {
-			domain.Light:  mapset.NewSet[string]("transition", "rgb_color", "rgbw_color", "rgbww_color", "color_name", "hs_color", "xy_color", "color_temp", "kelvin", "brightness", "brightness_pct", "brightness_step", "brightness_step_pct", "white", "profile", "flash", "effect"),
+			domain.Light:  mapset.NewSet[string]("transition", "rgb_color", "rgbw_color", "rgbww_color", "color_name", "hs_color", "xy_color", "color_temp", "color_temp_kelvin", "kelvin", "brightness", "brightness_pct", "brightness_step", "brightness_step_pct", "white", "profile", "flash", "effect"),
 			domain.Switch: mapset.NewSet[string](),
 		},
 	}
+
+	// colorTempServiceData are the service_data keys only sent to lights supporting color temperatures.
+	colorTempServiceData = mapset.NewSet[string]("color_temp", "color_temp_kelvin", "kelvin")
+
+	// colorTempModes are the color modes of lights that can display color temperatures.
+	colorTempModes = mapset.NewSet[string]("color_temp", "hs", "xy", "rgb", "rgbw", "rgbww")
 )
 
+// supportColorTemp is the legacy supported_features flag for color temperature support.
+const supportColorTemp = 2
+
 type HomeAssistant struct {
 	wsURL   *url.URL
 	httpURL *url.URL
@@ -56,10 +66,15 @@ type HomeAssistant struct {
 	states   map[EntityID]*State
 	statesMu sync.RWMutex
 
+	// our own service calls to recognize the state changes caused by them
+	ownCalls *ownCalls
+
 	// events received from the websocket connection
 	receivedEvents chan *EventMsg
-	// time the most recent event was received
-	lastEventReceived time.Time
+	// entities whose state changes are forwarded - nil forwards the state changes of all entities
+	forwardedStates atomic.Pointer[mapset.Set[EntityID]]
+	// time the most recent event was received (unix nanoseconds)
+	lastEventReceived atomic.Int64
 	lastEventTicker   *time.Ticker
 
 	// map of the result handlers for sent messages/requests
@@ -74,7 +89,9 @@ type HomeAssistant struct {
 	nonce atomic.Int64
 
 	// websocket connection
-	conn *websocket.Conn
+	conn atomic.Pointer[websocket.Conn]
+	// authenticated is true while the connection is authenticated
+	authenticated atomic.Bool
 	// lock for the websocket
 	wsMutex sync.Mutex
 
@@ -133,10 +150,11 @@ func createInstance(rawURL string, token string, eventsChannel *chan *EventMsg)
 
 		states: make(map[EntityID]*State),
 
+		ownCalls: newOwnCalls(),
+
 		receivedEvents: *eventsChannel,
 
-		lastEventReceived: time.Now(),
-		lastEventTicker:   time.NewTicker(viper.GetDuration("homeassistant.defaults.watchdog_check_every")),
+		lastEventTicker: time.NewTicker(viper.GetDuration("homeassistant.defaults.watchdog_check_every")),
 
 		nonce: atomic.Int64{},
 
@@ -151,6 +169,8 @@ func createInstance(rawURL string, token string, eventsChannel *chan *EventMsg)
 		startTime: time.Now(),
 	}
 
+	homAss.lastEventReceived.Store(time.Now().UnixNano())
+
 	return homAss, nil
 }
 
@@ -159,7 +179,7 @@ func (ha *HomeAssistant) setup() {
 	initialSetup := true
 
 	// shutdown current connection
-	if ha.conn != nil {
+	if ha.conn.Load() != nil {
 		initialSetup = false
 
 		ha.pr.Infof("%s reconnect - closing existing connection...", icons.Stopwatch)
@@ -196,6 +216,8 @@ func (ha *HomeAssistant) setup() {
 	}
 
 	if !initialSetup {
+		metrics.Reconnects.Inc()
+
 		ha.pr.Printf("%s reconnected", icons.GreenTick)
 	}
 }
@@ -210,12 +232,12 @@ func (ha *HomeAssistant) setupConnection() error {
 		return err
 	}
 
-	ha.conn = conn
+	ha.conn.Store(conn)
 
 	ha.pr.Infof("%s connected to %s", icons.ConnectionChain, ha.wsURL.String())
 
 	// increase max size of a message for the connection (in bytes)
-	ha.conn.SetReadLimit(readLimit)
+	conn.SetReadLimit(readLimit)
 
 	ha.pr.Infof("%s increased message read limit to %s bytes", icons.Glasses, style.Bold(strconv.Itoa(int(readLimit))))
 
@@ -226,6 +248,8 @@ func (ha *HomeAssistant) setupConnection() error {
 		return err
 	}
 
+	ha.authenticated.Store(true)
+
 	ha.pr.Infof("%s successfully authenticated", icons.Key)
 
 	return nil
@@ -263,18 +287,16 @@ func (ha *HomeAssistant) shutdown() {
 	ha.lastEventTicker.Stop()
 
 	// try graceful close of the existing connection
-	if ha.conn != nil {
-		ha.pr.Debugf("%s closing existing connection... %+v", icons.RedCross.Render(), ha.conn)
+	if conn := ha.conn.Load(); conn != nil {
+		ha.pr.Debugf("%s closing existing connection... %+v", icons.RedCross.Render(), conn)
 
-		if err := ha.conn.Close(websocket.StatusNormalClosure, "reconnect"); err != nil {
+		if err := conn.Close(websocket.StatusNormalClosure, "reconnect"); err != nil {
 			ha.pr.Debugf("%s failed to gracefully close connection: %+v", icons.RedCross.Render(), err)
 
 			// force close
-			if ha.conn != nil {
-				ha.pr.Debugf("🤷%s force closing the connection... %#v", icons.Shrug, ha.conn)
+			ha.pr.Debugf("🤷%s force closing the connection... %#v", icons.Shrug, conn)
 
-				_ = ha.conn.CloseNow()
-			}
+			_ = conn.CloseNow()
 		}
 	}
 
@@ -293,7 +315,8 @@ func (ha *HomeAssistant) shutdown() {
 	ha.resultsHandler = make(map[int64]*chan ResultMsg)
 
 	// clear websocket connection
-	ha.conn = nil
+	ha.conn.Store(nil)
+	ha.authenticated.Store(false)
 
 	// clear nonce
 	ha.nonce.Store(1337)
@@ -305,7 +328,7 @@ func (ha *HomeAssistant) doAuthentication() error {
 	var versionMsg VersionMsg
 
 	// read first message...
-	err := wsjson.Read(context.TODO(), ha.conn, &versionMsg)
+	err := wsjson.Read(context.TODO(), ha.conn.Load(), &versionMsg)
 	if err != nil {
 		ha.pr.Error(fmt.Errorf("failed to read message: %w", err))
 
@@ -318,14 +341,14 @@ func (ha *HomeAssistant) doAuthentication() error {
 	}
 
 	// reply with auth message containing a token
-	err = wsjson.Write(context.TODO(), ha.conn, NewAuthMsg(ha.token))
+	err = wsjson.Write(context.TODO(), ha.conn.Load(), NewAuthMsg(ha.token))
 	if err != nil {
 		ha.pr.Error(fmt.Errorf("failed to write message: %w", err))
 
 		return err
 	}
 
-	err = wsjson.Read(context.TODO(), ha.conn, &versionMsg)
+	err = wsjson.Read(context.TODO(), ha.conn.Load(), &versionMsg)
 	if err != nil {
 		ha.pr.Error(fmt.Errorf("failed to read message: %w", err))
 
@@ -368,6 +391,24 @@ func (ha *HomeAssistant) FriendlyName(entityID EntityID) string {
 	return state.Attributes.FriendlyName
 }
 
+// SupportsColorTemp checks if the light supports color temperatures by its color modes or supported features.
+func (ha *HomeAssistant) SupportsColorTemp(entityID EntityID) bool {
+	state := ha.GetState(entityID)
+	if state == nil {
+		return false
+	}
+
+	if modes, ok := state.Attributes.Other["supported_color_modes"].([]interface{}); ok {
+		for _, mode := range modes {
+			if colorTempModes.Contains(fmt.Sprint(mode)) {
+				return true
+			}
+		}
+	}
+
+	return state.Attributes.SupportedFeatures&supportColorTemp != 0
+}
+
 func (ha *HomeAssistant) TurnOn(targets []EntityID, serviceData map[string]interface{}) mapset.Set[*ResultMsg] {
 	return ha.turnOnOff(targets, service.TurnOn, serviceData)
 }
@@ -386,17 +427,37 @@ func (ha *HomeAssistant) turnOnOff(targets []EntityID, haService service.Service
 
 		filteredServiceData := filterServiceData(serviceData, allowedServiceData[haService][target.Domain()])
 
+		// do not send color temperatures to lights that cannot display them
+		if target.Domain() == domain.Light && !ha.SupportsColorTemp(target) {
+			for key := range colorTempServiceData.Iter() {
+				delete(filteredServiceData, key)
+			}
+		}
+
+		// lights switched by scenes & groups
+		members := ha.members(target)
+
 		go func(target EntityID) {
 			// call service
+			ha.ownCalls.start(target, members)
+
 			result, err := ha.wsCallWithResponse(NewCallServiceMsg(haService, filteredServiceData, target))
 			if result == nil || err != nil {
 				ha.pr.Warnf("call(s) failed | %s for %s: %+v ||| %+v", haService, target.ID, result, err)
 
+				metrics.ServiceCallFailures.WithLabelValues(target.Domain().String(), haService.String()).Inc()
+
+				ha.ownCalls.done(target, members, stateContext{})
+
 				waitGroup.Done()
 
 				return
 			}
 
+			// remember the context to recognize the state changes caused by this call
+			resultContext, _ := result.Context()
+			ha.ownCalls.done(target, members, resultContext)
+
 			results.Add(result)
 
 			if result.Success {
@@ -408,6 +469,8 @@ func (ha *HomeAssistant) turnOnOff(targets []EntityID, haService service.Service
 				ha.pr.Debugf("%s %s %s", icons.Call, result, icons.GreenTick.String())
 			} else {
 				ha.pr.Warnf("%s %s %s", icons.Call, result, icons.RedCross.String())
+
+				metrics.ServiceCallFailures.WithLabelValues(target.Domain().String(), haService.String()).Inc()
 			}
 
 			waitGroup.Done()
@@ -419,6 +482,44 @@ func (ha *HomeAssistant) turnOnOff(targets []EntityID, haService service.Service
 	return results
 }
 
+// CallService calls a service without a target, e.g. notify.mobile_app_phone.
+func (ha *HomeAssistant) CallService(serviceDomain domain.Domain, haService service.Service, serviceData map[string]interface{}) error {
+	result, err := ha.wsCallWithResponse(NewDomainCallServiceMsg(serviceDomain, haService, serviceData))
+	if err != nil {
+		metrics.ServiceCallFailures.WithLabelValues(serviceDomain.String(), haService.String()).Inc()
+
+		return err
+	}
+
+	ha.pr.Debugf("%s %s %s", icons.Call, result, icons.GreenTick.String())
+
+	return nil
+}
+
+// FireEvent fires an event on the Home Assistant event bus.
+func (ha *HomeAssistant) FireEvent(eventType EventType, eventData map[string]interface{}) error {
+	result, err := ha.wsCallWithResponse(NewFireEventMsg(eventType, eventData))
+	if err != nil {
+		return err
+	}
+
+	ha.pr.Debugf("%s %s %s", icons.Call, result, icons.GreenTick.String())
+
+	return nil
+}
+
+// ForwardStates restricts the forwarded state_changed events to the given entities.
+func (ha *HomeAssistant) ForwardStates(entityIDs mapset.Set[EntityID]) {
+	ha.forwardedStates.Store(&entityIDs)
+}
+
+// isForwardedState checks if state changes of the given entity are forwarded.
+func (ha *HomeAssistant) isForwardedState(entityID EntityID) bool {
+	forwardedStates := ha.forwardedStates.Load()
+
+	return forwardedStates == nil || (*forwardedStates).Contains(entityID)
+}
+
 // SubscribeToEvent adds the given event to the subscriptions list and subscribes to it.
 func (ha *HomeAssistant) SubscribeToEvent(subscriptionEvent EventType) {
 	ha.SubscribeToEvents(mapset.NewSet[EventType](subscriptionEvent))
@@ -487,12 +588,13 @@ func (ha *HomeAssistant) wsCall(done *chan ResultMsg, msg Message) (int64, error
 		ha.resultsHandler[msgID] = done
 	}
 
-	if ha.conn == nil {
+	conn := ha.conn.Load()
+	if conn == nil {
 		return 0, models.ErrNoConnectionToWriteTo
 	}
 
 	// send the message
-	if err := wsjson.Write(context.Background(), ha.conn, msg); err != nil {
+	if err := wsjson.Write(context.Background(), conn, msg); err != nil {
 		return 0, err
 	}
 
@@ -549,6 +651,40 @@ func (ha *HomeAssistant) getStates() (int, error) {
 	return numStates, nil
 }
 
+// Config is the (partial) core configuration of Home Assistant.
+type Config struct {
+	Latitude     float64 `json:"latitude"      mapstructure:"latitude"`
+	Longitude    float64 `json:"longitude"     mapstructure:"longitude"`
+	Elevation    float64 `json:"elevation"     mapstructure:"elevation"`
+	TimeZone     string  `json:"time_zone"     mapstructure:"time_zone"`
+	LocationName string  `json:"location_name" mapstructure:"location_name"`
+	Version      string  `json:"version"       mapstructure:"version"`
+}
+
+// GetConfig fetches the core configuration from Home Assistant.
+func (ha *HomeAssistant) GetConfig() (*Config, error) {
+	// create ws message
+	msg := &baseMessage{Type: "get_config"}
+
+	// send message and wait for result
+	result, err := ha.wsCallWithResponse(msg)
+	if err != nil {
+		ha.pr.Error(fmt.Errorf("failed to get config: %w", err))
+
+		return nil, err
+	}
+
+	var config Config
+
+	if err := mapstructure.Decode(result.Result, &config); err != nil {
+		ha.pr.Error("❌ decoding incoming get_config result failed:", err)
+
+		return nil, err
+	}
+
+	return &config, nil
+}
+
 // updateStates updates the local state with the given states.
 func (ha *HomeAssistant) updateStates(states []*State) {
 	ha.statesMu.Lock()
@@ -589,13 +725,14 @@ func (ha *HomeAssistant) runReader() {
 func (ha *HomeAssistant) wsReader() error {
 	for {
 		// read message from websocket
-		if ha.conn == nil {
+		conn := ha.conn.Load()
+		if conn == nil {
 			return models.ErrNoConnectionToReadFrom
 		}
 
 		var msg map[string]interface{}
 
-		err := wsjson.Read(context.TODO(), ha.conn, &msg)
+		err := wsjson.Read(context.TODO(), conn, &msg)
 		if err != nil {
 			if websocket.CloseStatus(err) == websocket.StatusNormalClosure {
 				return models.ErrConnectionClosed
@@ -627,7 +764,7 @@ func (ha *HomeAssistant) wsReader() error {
 			ha.pr.Warnf("❔ received unexpected %s message: %+v", style.Bold(msgType), msg)
 		}
 
-		ha.lastEventReceived = time.Now()
+		ha.lastEventReceived.Store(time.Now().UnixNano())
 	}
 }
 
@@ -657,6 +794,11 @@ func (ha *HomeAssistant) handleEventMessage(msg map[string]interface{}) {
 
 		ha.pr.Debugf("%s updated state for %s: %+v", icons.Tick, eventMsg.Event.Data.EntityID.ID, ha.GetState(eventMsg.Event.Data.EntityID))
 
+		// forward state changes to track motion sensors, night mode entities, ...
+		if ha.isForwardedState(eventMsg.Event.Data.EntityID) {
+			ha.receivedEvents <- &eventMsg
+		}
+
 	// only forward subscribed events
 	case ha.subscriptions.Contains(eventMsg.Event.Type):
 		ha.receivedEvents <- &eventMsg
@@ -716,9 +858,9 @@ func (ha *HomeAssistant) lastEventReceivedWatchdog(maxAge, checkEvery time.Durat
 	ha.pr.Infof("%s starting last event received watchdog | max age: %s | check every: %s", icons.Watchdog, style.Bold(maxAge.String()), style.Bold(checkEvery.String()))
 
 	for range ha.lastEventTicker.C {
-		since := time.Since(ha.lastEventReceived)
+		since := time.Since(ha.lastEventReceivedAt())
 		if since > maxAge {
-			ha.pr.Warnf("❌ no events received for %s - reconnecting", style.Bold(time.Since(ha.lastEventReceived).String()))
+			ha.pr.Warnf("❌ no events received for %s - reconnecting", style.Bold(since.String()))
 
 			// reconnect
 			go ha.setup()
@@ -730,6 +872,11 @@ func (ha *HomeAssistant) lastEventReceivedWatchdog(maxAge, checkEvery time.Durat
 	}
 }
 
+// lastEventReceivedAt returns the time the most recent message was received.
+func (ha *HomeAssistant) lastEventReceivedAt() time.Time {
+	return time.Unix(0, ha.lastEventReceived.Load())
+}
+
 // filterServiceData filters the given service data map by the allowed keys.
 func filterServiceData(serviceData map[string]interface{}, allowedKeys mapset.Set[string]) map[string]interface{} {
 	if allowedKeys == nil {
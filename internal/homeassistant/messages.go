@@ -88,7 +88,7 @@ type CallServiceMsg struct {
 	Service     service.Service `json:"service"`
 	Domain      domain.Domain   `json:"domain"`
 	ServiceData interface{}     `json:"service_data,omitempty"`
-	Target      Target          `json:"target,omitempty"`
+	Target      *Target         `json:"target,omitempty"`
 }
 
 func (m *CallServiceMsg) String() string {
@@ -105,9 +105,12 @@ func (m *CallServiceMsg) String() string {
 	out.WriteString(m.baseMessage.framelessStringWithType())
 	out.WriteString(style.ColorizeHABlue("|"))
 	out.WriteString(style.Gray(6).Render("…") + lipgloss.NewStyle().Foreground(lipgloss.Color("#ddd")).Italic(true).Render(string(m.Service)))
-	out.WriteString(style.ColorizeHABlue(" → "))
-	out.WriteString(fmt.Sprint(m.Target.EntityID))
-	// out.WriteString(m.Target.EntityID.FmtString())
+
+	if m.Target != nil {
+		out.WriteString(style.ColorizeHABlue(" → "))
+		out.WriteString(fmt.Sprint(m.Target.EntityID))
+		// out.WriteString(m.Target.EntityID.FmtString())
+	}
 
 	if len(serviceData) > 0 {
 		out.WriteString(" " + style.HABlueFrame(fmtServiceData))
@@ -121,15 +124,23 @@ type Target struct {
 }
 
 func NewCallServiceMsg(service service.Service, serviceData map[string]interface{}, target EntityID) *CallServiceMsg {
+	serviceCallMsg := NewDomainCallServiceMsg(target.Domain(), service, serviceData)
+
+	serviceCallMsg.Target = &Target{
+		EntityID: target,
+	}
+
+	return serviceCallMsg
+}
+
+// NewDomainCallServiceMsg creates a service call without a target, e.g. for notify.* services.
+func NewDomainCallServiceMsg(serviceDomain domain.Domain, service service.Service, serviceData map[string]interface{}) *CallServiceMsg {
 	serviceCallMsg := &CallServiceMsg{
 		baseMessage: baseMessage{
 			Type: "call_service",
 		},
 		Service: service,
-		Domain:  target.Domain(),
-		Target: Target{
-			EntityID: target,
-		},
+		Domain:  serviceDomain,
 	}
 
 	if len(serviceData) > 0 {
@@ -163,6 +174,36 @@ func NewSubscribeMsg(eventType EventType) *SubscribeMsg {
 	}
 }
 
+type FireEventMsg struct {
+	baseMessage `mapstructure:",squash"`
+	EventType   EventType              `json:"event_type"`
+	EventData   map[string]interface{} `json:"event_data,omitempty"`
+}
+
+func (m *FireEventMsg) String() string {
+	out := strings.Builder{}
+
+	out.WriteString(m.baseMessage.framelessStringWithType())
+	out.WriteString(style.ColorizeHABlue(" → "))
+	out.WriteString(style.Bold(string(m.EventType)))
+
+	if len(m.EventData) > 0 {
+		out.WriteString(" " + style.HABlueFrame(fmt.Sprint(m.EventData)))
+	}
+
+	return style.HABlueFrame(out.String())
+}
+
+func NewFireEventMsg(eventType EventType, eventData map[string]interface{}) *FireEventMsg {
+	return &FireEventMsg{
+		baseMessage: baseMessage{
+			Type: "fire_event",
+		},
+		EventType: eventType,
+		EventData: eventData,
+	}
+}
+
 type EventMsg struct {
 	baseMessage `mapstructure:",squash"`
 	Event       *event `json:"event"           mapstructure:"event"`
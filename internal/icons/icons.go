@@ -7,16 +7,20 @@ const (
 	LightOn   = "💡"
 	LightOff  = "🌑"
 	AlreadyOn = "🔛"
+	Dim       = "🔅"
 
 	// motion/trigger related messages.
 	Trigger = "🫨 "
 	Motion  = "💃"
+	Button  = "🔘"
 
 	// reactions & related messages.
 	Blind = "🙈"
 	Sleep = "💤"
 	Hae   = "⁉️ ‽"
 	Block = "🚫"
+	Pause = "⏸️"
+	Play  = "▶️"
 
 	// connection related messages.
 	ConnectionFailed = "🔴"
@@ -26,6 +30,8 @@ const (
 
 	// daytime related messages.
 	Alarm = "⏰"
+	Moon  = "🌙"
+	Sun   = "🌤️"
 	Timer = "⏲️"
 
 	// other messages.
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "automoli"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	// EventsReceived counts all events received from Home Assistant.
+	EventsReceived = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_received_total",
+		Help:      "Events received from Home Assistant.",
+	})
+
+	// RoomEventsReceived counts the events forwarded to a room.
+	RoomEventsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "room_events_received_total",
+		Help:      "Events received per room.",
+	}, []string{"room"})
+
+	// EventToCall is the time between the event and the service call (etc).
+	EventToCall = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "event_to_call_seconds",
+		Help:      "Time between the triggering event and the service call.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"room", "service"})
+
+	// EventToLight is the time between the event and the finished service call (etl).
+	EventToLight = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "event_to_light_seconds",
+		Help:      "Time between the triggering event and the finished service call.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"room", "service"})
+
+	// TurnOnBlocked counts the turn ons prevented by the conditions of a room.
+	TurnOnBlocked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "turn_on_blocked_total",
+		Help:      "Motion events that did not turn on the lights, by reason.",
+	}, []string{"room", "reason"})
+
+	// LightsOnSeconds sums up the time the lights of a room were on.
+	LightsOnSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "lights_on_seconds_total",
+		Help:      "Time the lights of a room were on (counted when they are turned off).",
+	}, []string{"room"})
+
+	// Reconnects counts the reconnects to the Home Assistant websocket api.
+	Reconnects = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "homeassistant_reconnects_total",
+		Help:      "Reconnects to the Home Assistant websocket api.",
+	})
+
+	// ServiceCallFailures counts the failed service calls.
+	ServiceCallFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "homeassistant_service_call_failures_total",
+		Help:      "Failed Home Assistant service calls.",
+	}, []string{"domain", "service"})
+)
+
+// blockReasons maps the errors of the turn on checks to the reason label.
+var blockReasons = []struct {
+	err    error
+	reason string
+}{
+	{models.ErrAutoMoLiDisabled, "automoli_disabled"},
+	{models.ErrRoomDisabled, "room_disabled"},
+	{models.ErrRoomPaused, "paused"},
+	{models.ErrDaytimeDisabled, "daytime_disabled"},
+	{models.ErrLightAlreadyOn, "already_on"},
+	{models.ErrManualOverride, "manual_override"},
+	{models.ErrBlockedByCondition, "condition"},
+	{models.ErrIlluminanceAboveThreshold, "illuminance"},
+	{models.ErrLightJustTurnedOn, "just_turned_on"},
+}
+
+func init() { //nolint:gochecknoinits
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		EventsReceived,
+		RoomEventsReceived,
+		EventToCall,
+		EventToLight,
+		TurnOnBlocked,
+		LightsOnSeconds,
+		Reconnects,
+		ServiceCallFailures,
+	)
+}
+
+// BlockReason returns the reason label for the error of the turn on checks.
+func BlockReason(err error) string {
+	for _, blockReason := range blockReasons {
+		if errors.Is(err, blockReason.err) {
+			return blockReason.reason
+		}
+	}
+
+	return "other"
+}
+
+// Handler serves the metrics in the prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,98 @@
+package adaptive
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type Mode string
+
+const (
+	// Sun follows the elevation of the sun - warm & dim at sunrise/sunset, cold & bright at noon.
+	Sun Mode = "sun"
+	// Curve interpolates between the color_temp_kelvin & brightness anchors of the daytimes.
+	Curve Mode = "curve"
+)
+
+const (
+	DefaultMinKelvin        = 2200
+	DefaultMaxKelvin        = 5500
+	DefaultMinBrightnessPct = 30
+	DefaultMaxBrightnessPct = 100
+)
+
+// Adaptive holds the settings for adaptive (circadian) color temperature & brightness.
+type Adaptive struct {
+	// Mode is the way the values are calculated. Available options: sun & curve (default)
+	// the sun mode needs a location and replaces the brightness & color temperature set by the daytimes
+	Mode Mode `json:"mode,omitempty" mapstructure:"mode,omitempty"`
+
+	// MinKelvin & MaxKelvin limit the color temperature in the sun mode
+	MinKelvin uint16 `json:"min_kelvin,omitempty" mapstructure:"min_kelvin,omitempty"`
+	MaxKelvin uint16 `json:"max_kelvin,omitempty" mapstructure:"max_kelvin,omitempty"`
+
+	// MinBrightnessPct & MaxBrightnessPct limit the brightness in the sun mode
+	MinBrightnessPct uint8 `json:"min_brightness,omitempty" mapstructure:"min_brightness,omitempty"`
+	MaxBrightnessPct uint8 `json:"max_brightness,omitempty" mapstructure:"max_brightness,omitempty"`
+
+	// NudgeEvery adjusts lights that are already on in this interval (disabled if not set)
+	NudgeEvery time.Duration `json:"nudge_every,omitempty" mapstructure:"nudge_every,omitempty"`
+}
+
+// Values are the calculated color temperature & brightness (nil if not set).
+type Values struct {
+	ColorTempKelvin *uint16
+	BrightnessPct   *uint8
+}
+
+// IsEnabled checks if adaptive lighting is configured.
+func (a *Adaptive) IsEnabled() bool {
+	return a != nil
+}
+
+// SetDefaults fills the unset settings.
+func (a *Adaptive) SetDefaults() {
+	if a.Mode == "" {
+		a.Mode = Curve
+	}
+
+	if a.MinKelvin == 0 {
+		a.MinKelvin = DefaultMinKelvin
+	}
+
+	if a.MaxKelvin == 0 {
+		a.MaxKelvin = DefaultMaxKelvin
+	}
+
+	if a.MinBrightnessPct == 0 {
+		a.MinBrightnessPct = DefaultMinBrightnessPct
+	}
+
+	if a.MaxBrightnessPct == 0 {
+		a.MaxBrightnessPct = DefaultMaxBrightnessPct
+	}
+}
+
+func (a *Adaptive) String() string {
+	if a.Mode == Curve {
+		return string(a.Mode)
+	}
+
+	return fmt.Sprintf("%s %d-%dK %d-%d%%", a.Mode, a.MinKelvin, a.MaxKelvin, a.MinBrightnessPct, a.MaxBrightnessPct)
+}
+
+// ForSunFactor calculates the values for the given sun factor (0 = sun below the horizon, 1 = sun at its highest point of the day).
+func (a *Adaptive) ForSunFactor(factor float64) Values {
+	factor = math.Min(math.Max(factor, 0), 1)
+
+	kelvin := uint16(math.Round(Interpolate(float64(a.MinKelvin), float64(a.MaxKelvin), factor)))
+	brightnessPct := uint8(math.Round(Interpolate(float64(a.MinBrightnessPct), float64(a.MaxBrightnessPct), factor)))
+
+	return Values{ColorTempKelvin: &kelvin, BrightnessPct: &brightnessPct}
+}
+
+// Interpolate linearly interpolates between from & to (fraction 0 = from, 1 = to).
+func Interpolate(from, to, fraction float64) float64 {
+	return from + (to-from)*math.Min(math.Max(fraction, 0), 1)
+}
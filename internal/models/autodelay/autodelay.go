@@ -0,0 +1,78 @@
+package autodelay
+
+import (
+	"math"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	DefaultMin        = 1 * time.Minute
+	DefaultMax        = 30 * time.Minute
+	DefaultPercentile = 90
+	DefaultBumpWithin = 1 * time.Minute
+	DefaultBumpFactor = 1.25
+)
+
+// Settings hold the bounds & parameters for delays learned from the motion history (delay: auto).
+type Settings struct {
+	// Min & Max bound the learned delay
+	Min time.Duration `json:"min,omitempty" mapstructure:"min,omitempty"`
+	Max time.Duration `json:"max,omitempty" mapstructure:"max,omitempty"`
+
+	// Percentile of the recent gaps between motion events used as delay (default: 90)
+	Percentile float64 `json:"percentile,omitempty" mapstructure:"percentile,omitempty"`
+
+	// BumpWithin is the time after an automatic turn off in which manually turning the lights back on bumps the delay
+	BumpWithin time.Duration `json:"bump_within,omitempty" mapstructure:"bump_within,omitempty"`
+
+	// BumpFactor the delay is multiplied with on each bump (default: 1.25)
+	BumpFactor float64 `json:"bump_factor,omitempty" mapstructure:"bump_factor,omitempty"`
+}
+
+// SetDefaults fills the unset settings.
+func (s *Settings) SetDefaults() {
+	if s.Min <= 0 {
+		s.Min = DefaultMin
+	}
+
+	if s.Max <= 0 {
+		s.Max = DefaultMax
+	}
+
+	if s.Max < s.Min {
+		s.Max = s.Min
+	}
+
+	if s.Percentile <= 0 || s.Percentile > 100 {
+		s.Percentile = DefaultPercentile
+	}
+
+	if s.BumpWithin <= 0 {
+		s.BumpWithin = DefaultBumpWithin
+	}
+
+	if s.BumpFactor <= 1 {
+		s.BumpFactor = DefaultBumpFactor
+	}
+}
+
+// Clamp restricts the delay to the min & max bounds.
+func (s *Settings) Clamp(delay time.Duration) time.Duration {
+	return min(max(delay, s.Min), s.Max)
+}
+
+// Percentile returns the p-th percentile (nearest rank) of the given durations.
+func Percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
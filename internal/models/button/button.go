@@ -0,0 +1,36 @@
+package button
+
+import (
+	"time"
+
+	"github.com/benleb/automoli-go/internal/models/trigger"
+)
+
+type Action string
+
+const (
+	// Lock keeps the lights on until they are turned off the next time (pressing again unlocks).
+	Lock Action = "lock"
+	// Off turns off the lights and ignores motion for a while.
+	Off Action = "off"
+	// Cycle switches to the next daytime configuration of the day.
+	Cycle Action = "cycle"
+)
+
+func (a Action) IsValid() bool { return a == Lock || a == Off || a == Cycle }
+
+// Button maps the events of a remote/button (zha_event, deconz_event, hue_event, event.* entities, ...) to an action.
+type Button struct {
+	// Trigger matches the button events
+	trigger.Trigger `mapstructure:",squash"`
+
+	// Action is the action to run if the button is pressed
+	Action Action `json:"action" mapstructure:"action"`
+
+	// Duration is the time motion is ignored after the lights were turned off by the off action
+	Duration time.Duration `json:"duration,omitempty" mapstructure:"duration,omitempty"`
+}
+
+func (b *Button) String() string {
+	return string(b.Action) + " ← " + b.Trigger.String()
+}
@@ -0,0 +1,175 @@
+package condition
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/benleb/automoli-go/internal/models/expression"
+)
+
+type Operator string
+
+const (
+	Equal       Operator = "eq"
+	NotEqual    Operator = "ne"
+	GreaterThan Operator = "gt"
+	LessThan    Operator = "lt"
+	In          Operator = "in"
+)
+
+type Effect string
+
+const (
+	// BlockOn prevents the lights from being turned on while the condition holds.
+	BlockOn Effect = "block_on"
+	// BlockOff prevents the lights from being turned off while the condition holds.
+	BlockOff Effect = "block_off"
+)
+
+// Condition compares the state (or an attribute) of an entity with a value, e.g. "media_player.tv eq playing".
+type Condition struct {
+	// Entity is the entity whose state is compared
+	Entity homeassistant.EntityID `json:"entity" mapstructure:"entity"`
+
+	// Attribute compares the given attribute instead of the state
+	Attribute string `json:"attribute,omitempty" mapstructure:"attribute,omitempty"`
+
+	// Operator is the comparison. Available options: eq (default), ne, gt, lt & in
+	Operator Operator `json:"operator,omitempty" mapstructure:"operator,omitempty"`
+
+	// Value is the value to compare with (a list for the in operator)
+	Value interface{} `json:"value" mapstructure:"value"`
+
+	// Expression is an expression (instead of entity, operator & value) that holds if it returns true,
+	// e.g. "occupied && now.Hour() >= 22" - see the expression package for the available data
+	Expression *expression.Expression `json:"expression,omitempty" mapstructure:"expression,omitempty"`
+
+	// Effect is what happens while the condition holds. Available options: block_on & block_off
+	Effect Effect `json:"effect" mapstructure:"effect"`
+}
+
+// Validate checks the condition and sets the default operator.
+func (c *Condition) Validate() error {
+	if c.Operator == "" {
+		c.Operator = Equal
+	}
+
+	switch {
+	case c.Entity.ID == "" && c.Expression == nil:
+		return fmt.Errorf("%w: no entity or expression", models.ErrInvalidCondition)
+
+	case c.Operator != Equal && c.Operator != NotEqual && c.Operator != GreaterThan && c.Operator != LessThan && c.Operator != In:
+		return fmt.Errorf("%w: unknown operator %s", models.ErrInvalidCondition, c.Operator)
+
+	case c.Effect != BlockOn && c.Effect != BlockOff:
+		return fmt.Errorf("%w: unknown effect %s", models.ErrInvalidCondition, c.Effect)
+	}
+
+	return nil
+}
+
+// Holds checks if the condition is fulfilled by the given entity state (unknown states never fulfill a condition).
+func (c *Condition) Holds(state *homeassistant.State) bool {
+	if state == nil {
+		return false
+	}
+
+	var value interface{} = state.State
+
+	if c.Attribute != "" {
+		var ok bool
+
+		if value, ok = state.Attributes.Other[c.Attribute]; !ok {
+			return false
+		}
+	}
+
+	switch c.Operator {
+	case Equal, "":
+		return equals(value, c.Value)
+
+	case NotEqual:
+		return !equals(value, c.Value)
+
+	case GreaterThan, LessThan:
+		actual, okActual := toFloat(value)
+		expected, okExpected := toFloat(c.Value)
+
+		if !okActual || !okExpected {
+			return false
+		}
+
+		if c.Operator == GreaterThan {
+			return actual > expected
+		}
+
+		return actual < expected
+
+	case In:
+		values, ok := c.Value.([]interface{})
+		if !ok {
+			return equals(value, c.Value)
+		}
+
+		for _, expected := range values {
+			if equals(value, expected) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// HoldsIn checks if the condition is fulfilled - expressions are evaluated in the given environment.
+func (c *Condition) HoldsIn(env expression.Env) (bool, error) {
+	if c.Expression != nil {
+		return c.Expression.Bool(env)
+	}
+
+	return c.Holds(env.GetState(c.Entity)), nil
+}
+
+func (c *Condition) String() string {
+	if c.Expression != nil {
+		return c.Expression.String()
+	}
+
+	subject := c.Entity.ID
+	if c.Attribute != "" {
+		subject += "[" + c.Attribute + "]"
+	}
+
+	return fmt.Sprintf("%s %s %v", subject, c.Operator, c.Value)
+}
+
+// equals compares the values by their string representation as numbers may be decoded with different types.
+func equals(actual, expected interface{}) bool {
+	return fmt.Sprint(actual) == fmt.Sprint(expected)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	number := reflect.ValueOf(value)
+
+	switch number.Kind() { //nolint:exhaustive
+	case reflect.Float32, reflect.Float64:
+		return number.Float(), true
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(number.Int()), true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(number.Uint()), true
+
+	// strings & string types like json.Number
+	case reflect.String:
+		parsed, err := strconv.ParseFloat(number.String(), 64)
+
+		return parsed, err == nil
+	}
+
+	return 0, false
+}
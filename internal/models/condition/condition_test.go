@@ -0,0 +1,112 @@
+package condition
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/models"
+)
+
+// newState creates an entity state with the given attributes.
+func newState(state string, attributes map[string]interface{}) *homeassistant.State {
+	entityState := &homeassistant.State{State: state}
+	entityState.Attributes.Other = attributes
+
+	return entityState
+}
+
+func TestConditionHolds(t *testing.T) {
+	playing := newState("playing", map[string]interface{}{"volume_level": 0.4, "source": "TV"})
+	temperature := newState("21.5", nil)
+
+	tests := []struct {
+		name      string
+		condition Condition
+		state     *homeassistant.State
+		want      bool
+	}{
+		{"equal state", Condition{Operator: Equal, Value: "playing"}, playing, true},
+		{"default operator", Condition{Value: "playing"}, playing, true},
+		{"other state", Condition{Operator: Equal, Value: "paused"}, playing, false},
+		{"not equal", Condition{Operator: NotEqual, Value: "paused"}, playing, true},
+		{"number as state", Condition{Operator: Equal, Value: 21.5}, temperature, true},
+		{"greater than", Condition{Operator: GreaterThan, Value: 20}, temperature, true},
+		{"not greater than", Condition{Operator: GreaterThan, Value: "22"}, temperature, false},
+		{"less than", Condition{Operator: LessThan, Value: json.Number("22")}, temperature, true},
+		{"not less than", Condition{Operator: LessThan, Value: uint8(21)}, temperature, false},
+		{"non-numeric state", Condition{Operator: GreaterThan, Value: 20}, playing, false},
+		{"non-numeric value", Condition{Operator: LessThan, Value: "warm"}, temperature, false},
+		{"in list", Condition{Operator: In, Value: []interface{}{"paused", "playing"}}, playing, true},
+		{"not in list", Condition{Operator: In, Value: []interface{}{"paused", "idle"}}, playing, false},
+		{"in single value", Condition{Operator: In, Value: "playing"}, playing, true},
+		{"attribute", Condition{Attribute: "source", Operator: Equal, Value: "TV"}, playing, true},
+		{"numeric attribute", Condition{Attribute: "volume_level", Operator: GreaterThan, Value: 0.3}, playing, true},
+		{"missing attribute", Condition{Attribute: "media_title", Operator: NotEqual, Value: "news"}, playing, false},
+		{"unknown state", Condition{Operator: NotEqual, Value: "playing"}, nil, false},
+		{"unknown operator", Condition{Operator: "like", Value: "playing"}, playing, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.condition.Holds(test.state); got != test.want {
+				t.Errorf("%s holds = %t, want %t", test.condition.String(), got, test.want)
+			}
+		})
+	}
+}
+
+func TestConditionValidate(t *testing.T) {
+	tv := homeassistant.EntityID{ID: "media_player.tv"}
+
+	tests := []struct {
+		name      string
+		condition Condition
+		err       error
+	}{
+		{"valid", Condition{Entity: tv, Operator: NotEqual, Value: "off", Effect: BlockOff}, nil},
+		{"default operator", Condition{Entity: tv, Value: "playing", Effect: BlockOn}, nil},
+		{"no entity", Condition{Value: "playing", Effect: BlockOn}, models.ErrInvalidCondition},
+		{"unknown operator", Condition{Entity: tv, Operator: "like", Value: "playing", Effect: BlockOn}, models.ErrInvalidCondition},
+		{"unknown effect", Condition{Entity: tv, Value: "playing", Effect: "dim"}, models.ErrInvalidCondition},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.condition.Validate(); !errors.Is(err, test.err) {
+				t.Errorf("error = %v, want %v", err, test.err)
+			}
+
+			if test.err == nil && test.condition.Operator == "" {
+				t.Error("operator not defaulted")
+			}
+		})
+	}
+}
+
+func TestToFloat(t *testing.T) {
+	tests := []struct {
+		value  interface{}
+		want   float64
+		wantOK bool
+	}{
+		{21.5, 21.5, true},
+		{float32(0.5), 0.5, true},
+		{-3, -3, true},
+		{int64(42), 42, true},
+		{uint16(7), 7, true},
+		{"12.25", 12.25, true},
+		{json.Number("100"), 100, true},
+		{"unavailable", 0, false},
+		{true, 0, false},
+		{nil, 0, false},
+	}
+
+	for _, test := range tests {
+		got, ok := toFloat(test.value)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("toFloat(%#v) = %v, %t, want %v, %t", test.value, got, ok, test.want, test.wantOK)
+		}
+	}
+}
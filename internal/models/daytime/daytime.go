@@ -1,11 +1,17 @@
 package daytime
 
 import (
+	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/benleb/automoli-go/internal/models/condition"
 	"github.com/benleb/automoli-go/internal/models/flash"
+	"github.com/benleb/automoli-go/internal/models/sun"
+	"github.com/charmbracelet/log"
 )
 
 type Daytime struct {
@@ -16,7 +22,16 @@ type Daytime struct {
 	// motionDetection bool `mapstructure:"motion_detection"`
 
 	// Start is the time when the daytime should be activated
-	Start time.Time `json:"start" mapstructure:"start"`
+	Start Start `json:"start" mapstructure:"start"`
+
+	// Weekdays restricts the daytime to the given days of the week, e.g. "mon-fri" or [sat, sun] (default: every day)
+	Weekdays Weekdays `json:"weekdays,omitempty" mapstructure:"weekdays,omitempty"`
+
+	// Calendar restricts the daytime to days with an event in the given calendar
+	Calendar string `json:"calendar,omitempty" mapstructure:"calendar,omitempty"`
+
+	// ExceptCalendar restricts the daytime to days without an event in the given calendar
+	ExceptCalendar string `json:"except_calendar,omitempty" mapstructure:"except_calendar,omitempty"`
 
 	// LightConfiguration holds the light settings for the daytime
 	LightConfiguration `mapstructure:",squash"`
@@ -27,6 +42,15 @@ type Daytime struct {
 	// BrightnessPct is the brightness percentage to set for the target entities
 	BrightnessPct *uint8 `json:"brightness,omitempty" mapstructure:"brightness,omitempty"`
 
+	// ColorTempKelvin is the color temperature to set for the target entities (anchor of the adaptive curve mode)
+	ColorTempKelvin *uint16 `json:"color_temp_kelvin,omitempty" mapstructure:"color_temp_kelvin,omitempty"`
+
+	// IlluminanceThreshold overrides the illuminance threshold of the room for this daytime
+	IlluminanceThreshold *float64 `json:"illuminance_threshold,omitempty" mapstructure:"illuminance_threshold,omitempty"`
+
+	// Conditions block turning on/off the lights while this daytime is active (additionally to the room conditions)
+	Conditions []*condition.Condition `json:"conditions,omitempty" mapstructure:"conditions,omitempty"`
+
 	// ServiceData contains additional options that will be used to activate the daytime
 	// These settings will be sent to home assistant as "service data".
 	// check the home assistant "light.turn_on" service docs for available options
@@ -34,6 +58,23 @@ type Daytime struct {
 	ServiceData map[string]interface{} `json:"service_data,omitempty" mapstructure:"service_data,omitempty"`
 }
 
+// IsRestricted checks if the daytime is restricted to certain days.
+func (dt *Daytime) IsRestricted() bool {
+	return len(dt.Weekdays) > 0 || dt.Calendar != "" || dt.ExceptCalendar != ""
+}
+
+// NightMode holds a light configuration overriding the active daytime while the night mode entity is on.
+type NightMode struct {
+	// Entity controls the night mode, e.g. an input_boolean
+	Entity homeassistant.EntityID `json:"entity" mapstructure:"entity"`
+
+	// States are the entity states activating the night mode (default: on)
+	States []string `json:"states,omitempty" mapstructure:"states,omitempty"`
+
+	// Daytime holds the light settings (brightness, target, delay, ...) used while the night mode is active
+	Daytime `mapstructure:",squash"`
+}
+
 // ManualModeConfiguration holds settings for the manual mode (lights turned on manually).
 type ManualModeConfiguration struct {
 	// LockConfiguration is a flag to lock the light configuration if the light was manually turned on
@@ -45,7 +86,7 @@ type ManualModeConfiguration struct {
 
 // LightConfiguration holds settings controlling the light behavior.
 type LightConfiguration struct {
-	//  Delay is the time after which the lights should be turned off if no motion is detected.
+	//  Delay is the time after which the lights should be turned off if no motion is detected ("auto" learns it from the motion history).
 	Delay time.Duration `json:"delay,omitempty" mapstructure:"delay,omitempty"`
 
 	// Transition is the transition time in seconds to slowly turn on/off the lights
@@ -54,10 +95,18 @@ type LightConfiguration struct {
 	// Flash flashes the lights. Available options: short & long
 	Flash flash.Flash `json:"flash,omitempty" mapstructure:"flash,omitempty"`
 
+	// TransitionOnDaytimeSwitch applies the new daytime configuration to lights that are already on when the daytime switches
+	TransitionOnDaytimeSwitch *bool `json:"transition_on_daytime_switch,omitempty" mapstructure:"transition_on_daytime_switch,omitempty"`
+
 	// ManualModeConfiguration holds settings for the manual mode (lights turned on manually)
 	ManualModeConfiguration `json:"manual,omitempty" mapstructure:"manual,omitempty"`
 }
 
+// IsAutoDelay checks if the delay is learned from the motion history.
+func (lc *LightConfiguration) IsAutoDelay() bool {
+	return lc.Delay == AutoDelay
+}
+
 // targets is a set of home assistant entity IDs.
 type targets []homeassistant.EntityID
 
@@ -65,10 +114,203 @@ type targets []homeassistant.EntityID
 // (used by mapstructure to map string/slice from the config file to a slice).
 func (t *targets) UnmarshalText(text []byte) error {
 	for _, rawEntityID := range strings.Split(string(text), ";") {
-		if entityID, err := homeassistant.NewEntityID(rawEntityID); err == nil {
-			*t = append(*t, *entityID)
+		if rawEntityID = strings.TrimSpace(rawEntityID); rawEntityID == "" {
+			continue
+		}
+
+		// invalid targets are skipped at runtime - the validate command reports them as errors
+		entityID, err := homeassistant.NewEntityID(rawEntityID)
+		if err != nil {
+			log.Warnf("ignoring invalid target %s: %v", rawEntityID, err)
+
+			continue
+		}
+
+		*t = append(*t, *entityID)
+	}
+
+	return nil
+}
+
+// Start is the start time of a daytime - either a fixed wall-clock time like "19:45"
+// or a time relative to a sun event like "sunset-30m" or "sunrise+15m".
+type Start struct {
+	// Time is the start time on the day it was last resolved for
+	time.Time
+
+	// Event is the sun event the start time is relative to (empty for fixed start times)
+	Event sun.Event
+
+	// Offset is the offset to the sun event
+	Offset time.Duration
+}
+
+// IsSunRelative checks if the start time is relative to a sun event.
+func (s Start) IsSunRelative() bool {
+	return s.Event != ""
+}
+
+// IsResolved checks if the start time was resolved (fixed start times always are).
+func (s Start) IsResolved() bool {
+	return !s.Time.IsZero()
+}
+
+// On resolves the start time for the day of the given date.
+func (s Start) On(date time.Time, location *sun.Location) (time.Time, error) {
+	if !s.IsSunRelative() {
+		return time.Date(date.Year(), date.Month(), date.Day(), s.Hour(), s.Minute(), 0, 0, date.Location()), nil
+	}
+
+	eventTime, err := location.Time(s.Event, date)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return eventTime.Add(s.Offset).Truncate(time.Minute), nil
+}
+
+// String returns the start time as configured.
+func (s Start) String() string {
+	switch {
+	case !s.IsSunRelative():
+		return s.Format("15:04")
+	case s.Offset > 0:
+		return string(s.Event) + "+" + s.Offset.String()
+	case s.Offset < 0:
+		return string(s.Event) + s.Offset.String()
+	}
+
+	return string(s.Event)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+// (used by mapstructure to map "15:04" or "<sunrise|sunset>[+-offset]" strings to a start time).
+func (s *Start) UnmarshalText(text []byte) error {
+	rawStart := strings.ToLower(strings.TrimSpace(string(text)))
+
+	for _, event := range []sun.Event{sun.Sunrise, sun.Sunset} {
+		rawOffset, found := strings.CutPrefix(rawStart, string(event))
+		if !found {
+			continue
+		}
+
+		var offset time.Duration
+
+		if rawOffset != "" {
+			var err error
+
+			if offset, err = time.ParseDuration(rawOffset); err != nil || (rawOffset[0] != '+' && rawOffset[0] != '-') {
+				return fmt.Errorf("%w: %s", models.ErrInvalidStart, text)
+			}
 		}
+
+		*s = Start{Event: event, Offset: offset}
+
+		return nil
+	}
+
+	startTime, err := time.Parse("15:04", rawStart)
+	if err != nil {
+		return fmt.Errorf("%w: %s", models.ErrInvalidStart, text)
 	}
 
+	*s = Start{Time: startTime}
+
 	return nil
 }
+
+func (s Start) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s Start) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Weekday is a day of the week, configured by its name like "mon" or "monday".
+type Weekday time.Weekday
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (w *Weekday) UnmarshalText(text []byte) error {
+	rawWeekday := strings.ToLower(strings.TrimSpace(string(text)))
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if name := strings.ToLower(day.String()); rawWeekday == name || (len(rawWeekday) >= 2 && strings.HasPrefix(name, rawWeekday)) {
+			*w = Weekday(day)
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", models.ErrInvalidWeekday, text)
+}
+
+func (w Weekday) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+func (w Weekday) String() string {
+	return strings.ToLower(time.Weekday(w).String()[:3])
+}
+
+// Weekdays is a set of days of the week.
+type Weekdays []Weekday
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+// (used by mapstructure to map strings like "mon-fri" or "sat,sun" to a set of weekdays).
+func (w *Weekdays) UnmarshalText(text []byte) error {
+	weekdays := make(Weekdays, 0)
+
+	for _, rawDays := range strings.Split(string(text), ",") {
+		rawFirst, rawLast, isRange := strings.Cut(rawDays, "-")
+
+		var first, last Weekday
+
+		if err := first.UnmarshalText([]byte(rawFirst)); err != nil {
+			return err
+		}
+
+		if last = first; isRange {
+			if err := last.UnmarshalText([]byte(rawLast)); err != nil {
+				return err
+			}
+		}
+
+		// ranges may wrap around the end of the week, e.g. "fri-mon"
+		for day := first; ; day = (day + 1) % 7 {
+			weekdays = append(weekdays, day)
+
+			if day == last {
+				break
+			}
+		}
+	}
+
+	*w = weekdays
+
+	return nil
+}
+
+// Contains checks if the given day is part of the weekdays - an empty set contains all days.
+func (w Weekdays) Contains(day time.Weekday) bool {
+	if len(w) == 0 {
+		return true
+	}
+
+	for _, weekday := range w {
+		if time.Weekday(weekday) == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w Weekdays) String() string {
+	days := make([]string, 0, len(w))
+	for _, weekday := range w {
+		days = append(days, weekday.String())
+	}
+
+	return strings.Join(days, ",")
+}
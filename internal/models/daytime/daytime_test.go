@@ -0,0 +1,184 @@
+package daytime
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/benleb/automoli-go/internal/models/sun"
+)
+
+func TestStartUnmarshalText(t *testing.T) {
+	tests := []struct {
+		raw    string
+		event  sun.Event
+		offset time.Duration
+		clock  string
+		err    error
+	}{
+		{raw: "19:45", clock: "19:45"},
+		{raw: " 7:05 ", clock: "07:05"},
+		{raw: "sunset", event: sun.Sunset},
+		{raw: "sunset-30m", event: sun.Sunset, offset: -30 * time.Minute},
+		{raw: "Sunrise+1h15m", event: sun.Sunrise, offset: 75 * time.Minute},
+		{raw: "sunset30m", err: models.ErrInvalidStart},
+		{raw: "sunrise+soon", err: models.ErrInvalidStart},
+		{raw: "25:00", err: models.ErrInvalidStart},
+		{raw: "noon", err: models.ErrInvalidStart},
+		{raw: "", err: models.ErrInvalidStart},
+	}
+
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			var start Start
+
+			err := start.UnmarshalText([]byte(test.raw))
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error = %v, want %v", err, test.err)
+			}
+
+			if test.err != nil {
+				return
+			}
+
+			if start.Event != test.event || start.Offset != test.offset {
+				t.Errorf("start = %s%s, want %s%s", start.Event, start.Offset, test.event, test.offset)
+			}
+
+			if test.clock != "" && start.Format("15:04") != test.clock {
+				t.Errorf("start = %s, want %s", start.Format("15:04"), test.clock)
+			}
+		})
+	}
+}
+
+func TestStartString(t *testing.T) {
+	for _, raw := range []string{"19:45", "sunset", "sunset-30m0s", "sunrise+15m0s"} {
+		var start Start
+		if err := start.UnmarshalText([]byte(raw)); err != nil {
+			t.Fatalf("unexpected error for %s: %v", raw, err)
+		}
+
+		if start.String() != raw {
+			t.Errorf("String() = %s, want %s", start.String(), raw)
+		}
+	}
+}
+
+func TestStartOn(t *testing.T) {
+	cest := time.FixedZone("CEST", 2*60*60)
+	berlin := &sun.Location{Latitude: 52.52, Longitude: 13.405}
+	date := time.Date(2024, 6, 21, 15, 0, 0, 0, cest)
+
+	sunset, _ := berlin.Time(sun.Sunset, date)
+
+	tests := []struct {
+		raw      string
+		location *sun.Location
+		want     time.Time
+		err      error
+	}{
+		{"19:45", nil, time.Date(2024, 6, 21, 19, 45, 0, 0, cest), nil},
+		{"sunset", berlin, sunset.Truncate(time.Minute), nil},
+		{"sunset-30m", berlin, sunset.Add(-30 * time.Minute).Truncate(time.Minute), nil},
+		{"sunrise", nil, time.Time{}, models.ErrNoLocation},
+	}
+
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			var start Start
+			if err := start.UnmarshalText([]byte(test.raw)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := start.On(date, test.location)
+			if !errors.Is(err, test.err) || !got.Equal(test.want) {
+				t.Errorf("On() = %s, %v, want %s, %v", got, err, test.want, test.err)
+			}
+		})
+	}
+}
+
+func TestWeekdayUnmarshalText(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want time.Weekday
+		err  error
+	}{
+		{"mon", time.Monday, nil},
+		{"Thursday", time.Thursday, nil},
+		{" tu ", time.Tuesday, nil},
+		{"th", time.Thursday, nil},
+		{"sun", time.Sunday, nil},
+		{"s", 0, models.ErrInvalidWeekday},
+		{"mondays", 0, models.ErrInvalidWeekday},
+		{"funday", 0, models.ErrInvalidWeekday},
+	}
+
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			var weekday Weekday
+
+			err := weekday.UnmarshalText([]byte(test.raw))
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error = %v, want %v", err, test.err)
+			}
+
+			if test.err == nil && time.Weekday(weekday) != test.want {
+				t.Errorf("weekday = %s, want %s", time.Weekday(weekday), test.want)
+			}
+		})
+	}
+}
+
+func TestWeekdaysUnmarshalText(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+		err  error
+	}{
+		{"mon-fri", "mon,tue,wed,thu,fri", nil},
+		{"sat,sun", "sat,sun", nil},
+		{"fri-mon", "fri,sat,sun,mon", nil},
+		{"mon,wed-thu", "mon,wed,thu", nil},
+		{"wed-wed", "wed", nil},
+		{"mon-xyz", "", models.ErrInvalidWeekday},
+		{"", "", models.ErrInvalidWeekday},
+	}
+
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			var weekdays Weekdays
+
+			err := weekdays.UnmarshalText([]byte(test.raw))
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error = %v, want %v", err, test.err)
+			}
+
+			if weekdays.String() != test.want {
+				t.Errorf("weekdays = %s, want %s", weekdays, test.want)
+			}
+		})
+	}
+}
+
+func TestWeekdaysContains(t *testing.T) {
+	var weekend Weekdays
+	if err := weekend.UnmarshalText([]byte("sat-sun")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		isWeekend := day == time.Saturday || day == time.Sunday
+
+		if weekend.Contains(day) != isWeekend {
+			t.Errorf("weekend.Contains(%s) = %t, want %t", day, !isWeekend, isWeekend)
+		}
+
+		// no weekdays configured means every day
+		if !(Weekdays{}).Contains(day) {
+			t.Errorf("empty weekdays do not contain %s", day)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package daytime
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/mitchellh/mapstructure"
+)
+
+// AutoDelay is the delay of "delay: auto" - the delay is learned from the motion history.
+const AutoDelay time.Duration = -1
+
+// StringToDelayHookFunc maps "auto" to the AutoDelay sentinel (must run before the string to duration hook).
+func StringToDelayHookFunc() mapstructure.DecodeHookFunc { //nolint:ireturn
+	return func(f reflect.Type, targetType reflect.Type, data any) (any, error) {
+		if f.Kind() != reflect.String || targetType != reflect.TypeOf(time.Duration(0)) {
+			return data, nil
+		}
+
+		if rawDelay, ok := data.(string); ok && strings.EqualFold(strings.TrimSpace(rawDelay), "auto") {
+			return AutoDelay, nil
+		}
+
+		return data, nil
+	}
+}
+
+// StrictTargetsHookFunc rejects ";"-separated targets with invalid entity ids instead of skipping them
+// (must run before the text unmarshaller hook).
+func StrictTargetsHookFunc() mapstructure.DecodeHookFunc { //nolint:ireturn
+	return func(f reflect.Type, targetType reflect.Type, data any) (any, error) {
+		rawTargets, ok := data.(string)
+		if !ok || f.Kind() != reflect.String || targetType != reflect.TypeOf(targets{}) {
+			return data, nil
+		}
+
+		for _, rawEntityID := range strings.Split(rawTargets, ";") {
+			if rawEntityID = strings.TrimSpace(rawEntityID); rawEntityID == "" {
+				continue
+			}
+
+			if _, err := homeassistant.NewEntityID(rawEntityID); err != nil {
+				return nil, fmt.Errorf("invalid target: %w", err)
+			}
+		}
+
+		return data, nil
+	}
+}
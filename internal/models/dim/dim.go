@@ -0,0 +1,40 @@
+package dim
+
+import (
+	"time"
+)
+
+type Method string
+
+const (
+	// Step lowers the brightness by the configured brightness_step_pct.
+	Step Method = "step"
+	// Transition slowly fades out the lights over the dim period.
+	Transition Method = "transition"
+)
+
+// Dim holds the settings for dimming the lights as a warning before they are turned off.
+type Dim struct {
+	// Method is the way the lights are dimmed. Available options: step & transition
+	Method Method `json:"method,omitempty" mapstructure:"method,omitempty"`
+
+	// BrightnessStepPct is the brightness change in percent (negative values dim the lights) for the step method
+	BrightnessStepPct int8 `json:"brightness_step_pct,omitempty" mapstructure:"brightness_step_pct,omitempty"`
+
+	// SecondsBefore is the time in seconds before the lights are turned off to dim them
+	SecondsBefore uint `json:"seconds_before,omitempty" mapstructure:"seconds_before,omitempty"`
+}
+
+// Before returns the time before the lights are turned off to dim them.
+func (d *Dim) Before() time.Duration {
+	if d == nil {
+		return 0
+	}
+
+	return time.Duration(d.SecondsBefore) * time.Second
+}
+
+// IsEnabled checks if dimming is configured.
+func (d *Dim) IsEnabled() bool {
+	return d != nil && d.SecondsBefore > 0
+}
@@ -5,15 +5,35 @@ import (
 )
 
 const (
-	BinarySensor Domain = "binary_sensor"
-	InputBoolean Domain = "input_boolean"
-	Light        Domain = "light"
-	Scene        Domain = "scene"
-	Sensor       Domain = "sensor"
-	Switch       Domain = "switch"
+	BinarySensor  Domain = "binary_sensor"
+	Calendar      Domain = "calendar"
+	Cover         Domain = "cover"
+	DeviceTracker Domain = "device_tracker"
+	Event         Domain = "event"
+	Fan           Domain = "fan"
+	Group         Domain = "group"
+	InputBoolean  Domain = "input_boolean"
+	InputNumber   Domain = "input_number"
+	InputSelect   Domain = "input_select"
+	Light         Domain = "light"
+	MediaPlayer   Domain = "media_player"
+	Person        Domain = "person"
+	Remote        Domain = "remote"
+	Scene         Domain = "scene"
+	Script        Domain = "script"
+	Sensor        Domain = "sensor"
+	Switch        Domain = "switch"
+	Timer         Domain = "timer"
+	Zone          Domain = "zone"
+
+	// Notify is a service domain only - there are no notify entities.
+	Notify Domain = "notify"
 )
 
-var validDomains = mapset.NewSet(BinarySensor, InputBoolean, Light, Scene, Sensor, Switch)
+var validDomains = mapset.NewSet(
+	BinarySensor, Calendar, Cover, DeviceTracker, Event, Fan, Group, InputBoolean, InputNumber, InputSelect,
+	Light, MediaPlayer, Person, Remote, Scene, Script, Sensor, Switch, Timer, Zone,
+)
 
 type Domain string
 
@@ -14,19 +14,46 @@ var (
 	ErrNoConnectionToReadFrom = errors.New("no connection to read from")
 	ErrNoConnectionToWriteTo  = errors.New("no connection to write to")
 	ErrConnectionClosed       = errors.New("connection closed")
+	ErrConnectionTimeout      = errors.New("connection timed out")
 
 	// home assistant errors.
 	ErrNoStatesReceived      = errors.New("no states received")
 	ErrUnexpectedMessageType = errors.New("unexpected message type")
 	ErrEmptyEntityID         = errors.New("empty entity id")
 	ErrInvalidEntityID       = errors.New("invalid entity id")
+	ErrInvalidService        = errors.New("invalid service")
 
 	// light conditions.
 	ErrLightAlreadyOn    = errors.New("light is already on")
 	ErrLightJustTurnedOn = errors.New("light just turned on")
 	// ErrLightAlreadyOff   = errors.New("light is already off").
 	ErrAutoMoLiDisabled = errors.New("AutoMoLi is disabled")
+	ErrRoomDisabled     = errors.New("room is disabled")
+	ErrRoomPaused       = errors.New("room is paused")
+	ErrUnknownRoom      = errors.New("unknown room")
+	ErrUnknownDaytime   = errors.New("unknown daytime")
 	ErrDaytimeDisabled  = errors.New("disabled by light configuration for this daytime")
+	ErrManualOverride   = errors.New("lights controlled manually & configuration locked")
+	ErrNightModeActive  = errors.New("night mode active")
+
+	ErrIlluminanceAboveThreshold = errors.New("illuminance above threshold")
+	ErrBlockedByCondition        = errors.New("blocked by condition")
+	ErrInvalidCondition          = errors.New("invalid condition")
+	ErrInvalidExpression         = errors.New("invalid expression")
+
+	// daytime errors.
+	ErrInvalidStart    = errors.New("invalid daytime start")
+	ErrNoLocation      = errors.New("no location configured")
+	ErrNoSunriseSunset = errors.New("no sunrise/sunset at this location and date")
+	ErrUnknownSunEvent = errors.New("unknown sun event")
+	ErrInvalidWeekday  = errors.New("invalid weekday")
+
+	// calendar errors.
+	ErrUnknownCalendar = errors.New("unknown calendar")
+	ErrInvalidICSDate  = errors.New("invalid ics date")
+
+	// http api errors.
+	ErrUnauthorized = errors.New("missing or invalid token")
 )
 
 func InvalidEntityIDErr(rawEntityID string) error {
@@ -0,0 +1,163 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// Prefix marks service data values as expressions, e.g. "=num('sensor.lux') < 5 ? 20 : 60".
+const Prefix = "="
+
+// Expression is a compiled expr-lang expression (https://expr-lang.org) evaluated against the room & entity states.
+type Expression struct {
+	source  string
+	program *vm.Program
+}
+
+// Env holds the data available in expressions.
+//
+//	state("sensor.x")          state of an entity ("" if unknown)
+//	num("sensor.x")            state of an entity as number (0 if not numeric)
+//	attr("light.x", "brightness") attribute of an entity (nil if unknown)
+//	is_on("media_player.tv")   checks if the entity is on (on, playing, home, open, ...)
+//	room, daytime              name of the room & the active daytime
+//	lights_on, occupied, paused, manual  room runtime state
+//	now                        current time, e.g. now.Hour() >= 22
+type Env struct {
+	// GetState returns the current state of an entity
+	GetState func(homeassistant.EntityID) *homeassistant.State
+
+	Room    string
+	Daytime string
+
+	LightsOn bool
+	Occupied bool
+	Paused   bool
+	Manual   bool
+}
+
+// onStates are states considered as "on" by is_on.
+var onStates = []string{"on", "playing", "home", "open", "heat", "cool", "active"}
+
+// Compile compiles the source (with or without the expression prefix) and checks it against the environment.
+func Compile(source string) (*Expression, error) {
+	source = strings.TrimSpace(strings.TrimPrefix(source, Prefix))
+
+	program, err := expr.Compile(source, expr.Env(Env{}.vars()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", models.ErrInvalidExpression, source, err)
+	}
+
+	return &Expression{source: source, program: program}, nil
+}
+
+// Eval evaluates the expression in the given environment.
+func (e *Expression) Eval(env Env) (interface{}, error) {
+	return expr.Run(e.program, env.vars())
+}
+
+// Bool evaluates the expression and checks if the result is a boolean.
+func (e *Expression) Bool(env Env) (bool, error) {
+	result, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+
+	boolResult, ok := result.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: %s returned %v instead of a boolean", models.ErrInvalidExpression, e.source, result)
+	}
+
+	return boolResult, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+// (used by mapstructure to compile & validate the expressions while loading the config).
+func (e *Expression) UnmarshalText(text []byte) error {
+	compiled, err := Compile(string(text))
+	if err != nil {
+		return err
+	}
+
+	*e = *compiled
+
+	return nil
+}
+
+func (e *Expression) MarshalText() ([]byte, error) {
+	return []byte(Prefix + e.source), nil
+}
+
+// vars returns the variables & functions available in expressions.
+func (env Env) vars() map[string]interface{} {
+	return map[string]interface{}{
+		"state": env.state,
+		"num":   env.num,
+		"attr":  env.attr,
+		"is_on": env.isOn,
+
+		"room":    env.Room,
+		"daytime": env.Daytime,
+
+		"lights_on": env.LightsOn,
+		"occupied":  env.Occupied,
+		"paused":    env.Paused,
+		"manual":    env.Manual,
+
+		"now": time.Now(),
+	}
+}
+
+func (env Env) getState(rawEntityID string) *homeassistant.State {
+	if env.GetState == nil {
+		return nil
+	}
+
+	return env.GetState(homeassistant.EntityID{ID: rawEntityID})
+}
+
+func (env Env) state(rawEntityID string) string {
+	if state := env.getState(rawEntityID); state != nil {
+		return state.State
+	}
+
+	return ""
+}
+
+func (env Env) num(rawEntityID string) float64 {
+	number, _ := strconv.ParseFloat(env.state(rawEntityID), 64)
+
+	return number
+}
+
+func (env Env) attr(rawEntityID string, attribute string) interface{} {
+	state := env.getState(rawEntityID)
+	if state == nil {
+		return nil
+	}
+
+	return state.Attributes.Other[attribute]
+}
+
+func (env Env) isOn(rawEntityID string) bool {
+	currentState := env.state(rawEntityID)
+
+	for _, onState := range onStates {
+		if currentState == onState {
+			return true
+		}
+	}
+
+	return false
+}
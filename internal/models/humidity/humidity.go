@@ -0,0 +1,36 @@
+package humidity
+
+import (
+	"time"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+)
+
+// Rise holds the settings to detect a fast rise of the humidity (e.g. someone is taking a shower).
+type Rise struct {
+	// Rise is the humidity increase in percentage points relative to the baseline that triggers the shower case
+	Rise float64 `json:"rise" mapstructure:"rise"`
+
+	// Within is the time window the rise has to happen in (the lowest humidity within the window is the baseline)
+	Within time.Duration `json:"within,omitempty" mapstructure:"within,omitempty"`
+
+	// Hysteresis is the margin above the baseline the humidity has to fall below to release the shower case (default: half the rise)
+	Hysteresis *float64 `json:"hysteresis,omitempty" mapstructure:"hysteresis,omitempty"`
+
+	// ReferenceSensor is a humidity sensor (e.g. in another room) used as baseline instead of the remembered humidity
+	ReferenceSensor *homeassistant.EntityID `json:"reference_sensor,omitempty" mapstructure:"reference_sensor,omitempty"`
+}
+
+// IsEnabled checks if the rise detection is configured.
+func (r *Rise) IsEnabled() bool {
+	return r != nil && r.Rise > 0
+}
+
+// Margin returns the hysteresis margin above the baseline.
+func (r *Rise) Margin() float64 {
+	if r.Hysteresis == nil {
+		return r.Rise / 2
+	}
+
+	return *r.Hysteresis
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/models"
+	"github.com/benleb/automoli-go/internal/models/domain"
+	"github.com/benleb/automoli-go/internal/models/service"
+)
+
+// Notify holds the settings to send a notification if the lights were forgotten (on without motion for a while).
+type Notify struct {
+	// Service is the notify service to call, e.g. notify.mobile_app_phone
+	Service string `json:"service" mapstructure:"service"`
+
+	// After is the time without motion after which the notification is sent
+	After time.Duration `json:"after" mapstructure:"after"`
+
+	// Title & Message of the notification ({room} is replaced by the room name)
+	Title   string `json:"title,omitempty"   mapstructure:"title,omitempty"`
+	Message string `json:"message,omitempty" mapstructure:"message,omitempty"`
+}
+
+// IsEnabled checks if the notification is configured.
+func (n *Notify) IsEnabled() bool {
+	return n != nil && n.Service != "" && n.After > 0
+}
+
+// DomainService splits the service into its domain and service name, e.g. notify.mobile_app_phone → notify & mobile_app_phone.
+func (n *Notify) DomainService() (domain.Domain, service.Service, error) {
+	serviceDomain, serviceName, ok := strings.Cut(n.Service, ".")
+	if !ok || domain.Domain(serviceDomain) != domain.Notify || serviceName == "" {
+		return "", "", fmt.Errorf("%w: %s", models.ErrInvalidService, n.Service)
+	}
+
+	return domain.Notify, service.Service(serviceName), nil
+}
+
+// ServiceData returns the service data of the notification for the given room.
+func (n *Notify) ServiceData(room string) map[string]interface{} {
+	serviceData := map[string]interface{}{"message": strings.ReplaceAll(n.Message, "{room}", room)}
+
+	if n.Title != "" {
+		serviceData["title"] = strings.ReplaceAll(n.Title, "{room}", room)
+	}
+
+	return serviceData
+}
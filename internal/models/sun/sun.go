@@ -0,0 +1,129 @@
+package sun
+
+import (
+	"math"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/models"
+)
+
+type Event string
+
+const (
+	Sunrise Event = "sunrise"
+	Sunset  Event = "sunset"
+)
+
+const (
+	// julian date of the unix epoch.
+	julianUnixEpoch = 2440587.5
+	// julian date of the J2000.0 epoch.
+	julianJ2000 = 2451545.0
+
+	// sun elevation at sunrise/sunset, corrected for atmospheric refraction and the sun's diameter.
+	sunriseElevation = -0.833
+	// obliquity of the ecliptic.
+	earthTilt = 23.4397
+)
+
+// Location is a position on earth used to calculate the sun events.
+type Location struct {
+	Latitude  float64 `json:"latitude"  mapstructure:"latitude"`
+	Longitude float64 `json:"longitude" mapstructure:"longitude"`
+}
+
+func (e Event) IsValid() bool { return e == Sunrise || e == Sunset }
+
+// Times calculates sunrise & sunset at the location for the day of the given date.
+// it's based on the NOAA sunrise equation and is accurate to about a minute.
+func (l *Location) Times(date time.Time) (time.Time, time.Time, error) {
+	if l == nil {
+		return time.Time{}, time.Time{}, models.ErrNoLocation
+	}
+
+	// days since J2000.0 (at noon) for the given day
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	days := math.Ceil(toJulian(midnight) - julianJ2000 + 0.0008)
+
+	// mean solar time
+	meanSolarTime := days - l.Longitude/360
+
+	// solar mean anomaly
+	meanAnomaly := math.Mod(357.5291+0.98560028*meanSolarTime, 360)
+
+	// equation of the center & ecliptic longitude
+	center := 1.9148*sin(meanAnomaly) + 0.02*sin(2*meanAnomaly) + 0.0003*sin(3*meanAnomaly)
+	eclipticLongitude := math.Mod(meanAnomaly+center+180+102.9372, 360)
+
+	// solar transit
+	transit := julianJ2000 + meanSolarTime + 0.0053*sin(meanAnomaly) - 0.0069*sin(2*eclipticLongitude)
+
+	// declination of the sun
+	declination := math.Asin(sin(eclipticLongitude) * sin(earthTilt))
+
+	// hour angle
+	cosHourAngle := (sin(sunriseElevation) - sin(l.Latitude)*math.Sin(declination)) / (cos(l.Latitude) * math.Cos(declination))
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return time.Time{}, time.Time{}, models.ErrNoSunriseSunset
+	}
+
+	hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+
+	sunrise := fromJulian(transit - hourAngle/360).In(date.Location())
+	sunset := fromJulian(transit + hourAngle/360).In(date.Location())
+
+	return sunrise, sunset, nil
+}
+
+// Time returns the time of the given sun event at the location for the day of the given date.
+func (l *Location) Time(event Event, date time.Time) (time.Time, error) {
+	sunrise, sunset, err := l.Times(date)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch event {
+	case Sunrise:
+		return sunrise, nil
+	case Sunset:
+		return sunset, nil
+	}
+
+	return time.Time{}, models.ErrUnknownSunEvent
+}
+
+// Elevation calculates the elevation of the sun in degrees above the horizon at the location and time.
+func (l *Location) Elevation(t time.Time) (float64, error) {
+	if l == nil {
+		return 0, models.ErrNoLocation
+	}
+
+	days := toJulian(t) - julianJ2000
+
+	// solar mean anomaly & ecliptic longitude
+	meanAnomaly := math.Mod(357.5291+0.98560028*days, 360)
+	center := 1.9148*sin(meanAnomaly) + 0.02*sin(2*meanAnomaly) + 0.0003*sin(3*meanAnomaly)
+	eclipticLongitude := math.Mod(meanAnomaly+center+180+102.9372, 360)
+
+	// declination & right ascension
+	declination := math.Asin(sin(eclipticLongitude) * sin(earthTilt))
+	rightAscension := math.Atan2(sin(eclipticLongitude)*cos(earthTilt), cos(eclipticLongitude))
+
+	// hour angle from the sidereal time
+	hourAngle := (280.16+360.9856235*days+l.Longitude)*math.Pi/180 - rightAscension
+
+	elevation := math.Asin(sin(l.Latitude)*math.Sin(declination) + cos(l.Latitude)*math.Cos(declination)*math.Cos(hourAngle))
+
+	return elevation * 180 / math.Pi, nil
+}
+
+func toJulian(t time.Time) float64 {
+	return float64(t.Unix())/86400 + julianUnixEpoch
+}
+
+func fromJulian(julian float64) time.Time {
+	return time.Unix(0, int64((julian-julianUnixEpoch)*86400*float64(time.Second))).Round(time.Second)
+}
+
+func sin(degrees float64) float64 { return math.Sin(degrees * math.Pi / 180) }
+func cos(degrees float64) float64 { return math.Cos(degrees * math.Pi / 180) }
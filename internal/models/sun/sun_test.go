@@ -0,0 +1,125 @@
+package sun
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/benleb/automoli-go/internal/models"
+)
+
+var (
+	berlin  = &Location{Latitude: 52.52, Longitude: 13.405}
+	newYork = &Location{Latitude: 40.7128, Longitude: -74.006}
+	tromso  = &Location{Latitude: 69.65, Longitude: 18.96}
+
+	cest = time.FixedZone("CEST", 2*60*60)
+	est  = time.FixedZone("EST", -5*60*60)
+)
+
+func TestTimes(t *testing.T) {
+	tests := []struct {
+		name     string
+		location *Location
+		date     time.Time
+		sunrise  time.Time
+		sunset   time.Time
+	}{
+		{"berlin summer solstice", berlin, time.Date(2024, 6, 21, 12, 0, 0, 0, cest), time.Date(2024, 6, 21, 4, 43, 0, 0, cest), time.Date(2024, 6, 21, 21, 33, 0, 0, cest)},
+		{"new york winter solstice", newYork, time.Date(2024, 12, 21, 12, 0, 0, 0, est), time.Date(2024, 12, 21, 7, 16, 0, 0, est), time.Date(2024, 12, 21, 16, 32, 0, 0, est)},
+		// west of UTC the local evening is already the next day in UTC
+		{"new york late evening", newYork, time.Date(2024, 12, 21, 23, 30, 0, 0, est), time.Date(2024, 12, 21, 7, 16, 0, 0, est), time.Date(2024, 12, 21, 16, 32, 0, 0, est)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sunrise, sunset, err := test.location.Times(test.date)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// the sunrise equation is accurate to about a minute
+			if diff := sunrise.Sub(test.sunrise); diff.Abs() > 2*time.Minute {
+				t.Errorf("sunrise = %s, want %s", sunrise, test.sunrise)
+			}
+
+			if diff := sunset.Sub(test.sunset); diff.Abs() > 2*time.Minute {
+				t.Errorf("sunset = %s, want %s", sunset, test.sunset)
+			}
+
+			if sunrise.Location() != test.date.Location() {
+				t.Errorf("sunrise in %s, want %s", sunrise.Location(), test.date.Location())
+			}
+		})
+	}
+}
+
+func TestTimesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		location *Location
+		err      error
+	}{
+		{"no location", nil, models.ErrNoLocation},
+		{"midnight sun", tromso, models.ErrNoSunriseSunset},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := test.location.Times(time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC)); !errors.Is(err, test.err) {
+				t.Errorf("error = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestTime(t *testing.T) {
+	date := time.Date(2024, 6, 21, 12, 0, 0, 0, cest)
+
+	sunrise, sunset, _ := berlin.Times(date)
+
+	tests := []struct {
+		event Event
+		want  time.Time
+		err   error
+	}{
+		{Sunrise, sunrise, nil},
+		{Sunset, sunset, nil},
+		{Event("noon"), time.Time{}, models.ErrUnknownSunEvent},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.event), func(t *testing.T) {
+			got, err := berlin.Time(test.event, date)
+			if !errors.Is(err, test.err) || !got.Equal(test.want) {
+				t.Errorf("Time(%s) = %s, %v, want %s, %v", test.event, got, err, test.want, test.err)
+			}
+		})
+	}
+}
+
+func TestElevation(t *testing.T) {
+	tests := []struct {
+		name string
+		time time.Time
+		want float64
+	}{
+		{"berlin solar noon", time.Date(2024, 6, 21, 13, 14, 0, 0, cest), 61},
+		{"berlin sunset", time.Date(2024, 6, 21, 21, 33, 0, 0, cest), sunriseElevation},
+		{"berlin midnight", time.Date(2024, 6, 22, 1, 14, 0, 0, cest), -14},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			elevation, err := berlin.Elevation(test.time)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if math.Abs(elevation-test.want) > 1 {
+				t.Errorf("elevation = %.2f°, want %.2f°", elevation, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package trigger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+)
+
+// Trigger is an event that is treated like motion in a room, e.g. a zha_event of a remote or a custom event.
+type Trigger struct {
+	// EventType is the type of the event, e.g. zha_event, deconz_event, hue_event or any custom event
+	EventType homeassistant.EventType `json:"event_type" mapstructure:"event_type"`
+
+	// Match are the event data fields and their accepted value(s), e.g. { device_ieee: "00:11:...", command: [on, toggle] }
+	// the fields entity_id & state refer to the entity id and the new state of state_changed events
+	Match map[string]interface{} `json:"match,omitempty" mapstructure:"match,omitempty"`
+}
+
+// Matches checks if the event is of the trigger's type and all match fields have an accepted value.
+func (t *Trigger) Matches(event *homeassistant.EventMsg) bool {
+	if event == nil || event.Event == nil || event.Event.Type != t.EventType {
+		return false
+	}
+
+	for field, accepted := range t.Match {
+		value, ok := fieldValue(event, field)
+		if !ok || !isAccepted(value, accepted) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *Trigger) String() string {
+	fields := make([]string, 0, len(t.Match))
+	for field, accepted := range t.Match {
+		fields = append(fields, field+"="+fmt.Sprint(accepted))
+	}
+
+	sort.Strings(fields)
+
+	if len(fields) == 0 {
+		return string(t.EventType)
+	}
+
+	return string(t.EventType) + " " + strings.Join(fields, " ")
+}
+
+// fieldValue looks up the value of the field in the event data or the attributes of the new state.
+func fieldValue(event *homeassistant.EventMsg, field string) (interface{}, bool) {
+	data := event.Event.Data
+
+	switch field {
+	case "entity_id":
+		return data.EntityID.ID, data.EntityID.ID != ""
+
+	case "state":
+		return data.NewState.State, data.NewState.State != ""
+	}
+
+	if value, ok := data.Other[field]; ok {
+		return value, true
+	}
+
+	value, ok := data.NewState.Attributes.Other[field]
+
+	return value, ok
+}
+
+// isAccepted checks if the value equals the accepted value or one of the accepted values.
+// values are compared by their string representation as numbers may be decoded with different types.
+func isAccepted(value interface{}, accepted interface{}) bool {
+	acceptedValues, ok := accepted.([]interface{})
+	if !ok {
+		acceptedValues = []interface{}{accepted}
+	}
+
+	for _, acceptedValue := range acceptedValues {
+		if fmt.Sprint(value) == fmt.Sprint(acceptedValue) {
+			return true
+		}
+	}
+
+	return false
+}
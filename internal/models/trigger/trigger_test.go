@@ -0,0 +1,102 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/benleb/automoli-go/internal/homeassistant"
+	"github.com/mitchellh/mapstructure"
+)
+
+// newEvent decodes the raw event like events received from home assistant.
+func newEvent(t *testing.T, eventType string, data map[string]interface{}) *homeassistant.EventMsg {
+	t.Helper()
+
+	var event homeassistant.EventMsg
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: homeassistant.StringToEntityIDHookFunc(),
+		Result:     &event,
+	})
+	if err != nil {
+		t.Fatalf("creating decoder: %v", err)
+	}
+
+	if err := decoder.Decode(map[string]interface{}{
+		"type":  "event",
+		"event": map[string]interface{}{"event_type": eventType, "data": data},
+	}); err != nil {
+		t.Fatalf("decoding event: %v", err)
+	}
+
+	return &event
+}
+
+func TestTriggerMatches(t *testing.T) {
+	remote := map[string]interface{}{"device_ieee": "00:11:22", "command": "on", "args": []interface{}{}, "endpoint_id": 1}
+	stateChange := map[string]interface{}{
+		"entity_id": "binary_sensor.kitchen_door",
+		"new_state": map[string]interface{}{
+			"entity_id":  "binary_sensor.kitchen_door",
+			"state":      "on",
+			"attributes": map[string]interface{}{"friendly_name": "Kitchen Door", "battery": 87},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		trigger   Trigger
+		eventType string
+		data      map[string]interface{}
+		want      bool
+	}{
+		{"event type only", Trigger{EventType: "zha_event"}, "zha_event", remote, true},
+		{"other event type", Trigger{EventType: "deconz_event"}, "zha_event", remote, false},
+		{"single value", Trigger{EventType: "zha_event", Match: map[string]interface{}{"command": "on"}}, "zha_event", remote, true},
+		{"wrong value", Trigger{EventType: "zha_event", Match: map[string]interface{}{"command": "off"}}, "zha_event", remote, false},
+		{"one of the values", Trigger{EventType: "zha_event", Match: map[string]interface{}{"command": []interface{}{"toggle", "on"}}}, "zha_event", remote, true},
+		{"none of the values", Trigger{EventType: "zha_event", Match: map[string]interface{}{"command": []interface{}{"toggle", "off"}}}, "zha_event", remote, false},
+		{"all fields", Trigger{EventType: "zha_event", Match: map[string]interface{}{"command": "on", "device_ieee": "00:11:22"}}, "zha_event", remote, true},
+		{"one field not matching", Trigger{EventType: "zha_event", Match: map[string]interface{}{"command": "on", "device_ieee": "99:88:77"}}, "zha_event", remote, false},
+		{"missing field", Trigger{EventType: "zha_event", Match: map[string]interface{}{"cluster_id": 6}}, "zha_event", remote, false},
+		{"number as string", Trigger{EventType: "zha_event", Match: map[string]interface{}{"endpoint_id": "1"}}, "zha_event", remote, true},
+		{"entity id", Trigger{EventType: "state_changed", Match: map[string]interface{}{"entity_id": "binary_sensor.kitchen_door"}}, "state_changed", stateChange, true},
+		{"other entity id", Trigger{EventType: "state_changed", Match: map[string]interface{}{"entity_id": "binary_sensor.hallway_door"}}, "state_changed", stateChange, false},
+		{"new state", Trigger{EventType: "state_changed", Match: map[string]interface{}{"entity_id": "binary_sensor.kitchen_door", "state": "on"}}, "state_changed", stateChange, true},
+		{"other new state", Trigger{EventType: "state_changed", Match: map[string]interface{}{"state": "off"}}, "state_changed", stateChange, false},
+		{"attribute of the new state", Trigger{EventType: "state_changed", Match: map[string]interface{}{"battery": 87}}, "state_changed", stateChange, true},
+		{"state without a state change", Trigger{EventType: "zha_event", Match: map[string]interface{}{"state": "on"}}, "zha_event", remote, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.trigger.Matches(newEvent(t, test.eventType, test.data)); got != test.want {
+				t.Errorf("%s matches = %t, want %t", test.trigger.String(), got, test.want)
+			}
+		})
+	}
+}
+
+func TestTriggerMatchesNil(t *testing.T) {
+	trigger := Trigger{EventType: "zha_event"}
+
+	if trigger.Matches(nil) || trigger.Matches(&homeassistant.EventMsg{}) {
+		t.Error("trigger matches an empty event")
+	}
+}
+
+func TestTriggerString(t *testing.T) {
+	tests := []struct {
+		trigger Trigger
+		want    string
+	}{
+		{Trigger{EventType: "zha_event"}, "zha_event"},
+		{Trigger{EventType: "zha_event", Match: map[string]interface{}{"device_ieee": "00:11:22", "command": "on"}}, "zha_event command=on device_ieee=00:11:22"},
+		{Trigger{EventType: "zha_event", Match: map[string]interface{}{"command": []interface{}{"on", "toggle"}}}, "zha_event command=[on toggle]"},
+	}
+
+	for _, test := range tests {
+		if got := test.trigger.String(); got != test.want {
+			t.Errorf("String() = %q, want %q", got, test.want)
+		}
+	}
+}
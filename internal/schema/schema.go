@@ -0,0 +1,208 @@
+package schema
+
+import (
+	"encoding"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Draft is the JSON Schema dialect of the generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Schema is the subset of a JSON Schema used to describe the config file.
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	ID          string `json:"$id,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type    string        `json:"type,omitempty"`
+	Enum    []interface{} `json:"enum,omitempty"`
+	Pattern string        `json:"pattern,omitempty"`
+	Minimum *float64      `json:"minimum,omitempty"`
+	Maximum *float64      `json:"maximum,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	PropertyNames        *Schema            `json:"propertyNames,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+
+	Items *Schema   `json:"items,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Generator derives JSON Schemas from (config) structs via their mapstructure tags.
+type Generator struct {
+	// Types maps types with custom decoding (decode hooks & text unmarshalers) to their schema
+	Types map[reflect.Type]*Schema
+
+	// Enums are the valid values of (string) types
+	Enums map[reflect.Type][]interface{}
+
+	// Fields overrides the schema of single struct fields, keyed by "<type>.<field>", e.g. "daytime.LightConfiguration.Delay"
+	Fields map[string]*Schema
+
+	// Required are the required keys of struct types (not inherited by structs embedding them)
+	Required map[reflect.Type][]string
+
+	// defs holds the schemas of the structs referenced by $ref
+	defs map[string]*Schema
+}
+
+// NewGenerator creates a generator without any custom types.
+func NewGenerator() *Generator {
+	return &Generator{
+		Types:    make(map[reflect.Type]*Schema),
+		Enums:    make(map[reflect.Type][]interface{}),
+		Fields:   make(map[string]*Schema),
+		Required: make(map[reflect.Type][]string),
+
+		defs: make(map[string]*Schema),
+	}
+}
+
+// Defs returns the schemas of all structs referenced so far.
+func (g *Generator) Defs() map[string]*Schema {
+	return g.defs
+}
+
+// Reflect returns the schema of the type - structs are added to the definitions and referenced.
+func (g *Generator) Reflect(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if custom, ok := g.Types[t]; ok {
+		return custom
+	}
+
+	if enum, ok := g.Enums[t]; ok {
+		return &Schema{Type: "string", Enum: enum}
+	}
+
+	// strings like "mon-fri" or lists like [sat, sun]
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		if t.Kind() == reflect.Slice {
+			return &Schema{OneOf: []*Schema{{Type: "string"}, {Type: "array", Items: g.Reflect(t.Elem())}}}
+		}
+
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		name := t.String()
+
+		if _, ok := g.defs[name]; !ok {
+			// placeholder for recursive types
+			g.defs[name] = &Schema{}
+			g.defs[name] = g.Struct(t)
+		}
+
+		return &Schema{Ref: "#/$defs/" + name}
+
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.Reflect(t.Elem())}
+
+	case reflect.Map:
+		mapSchema := &Schema{Type: "object", AdditionalProperties: g.Reflect(t.Elem())}
+
+		if keySchema := g.Reflect(t.Key()); keySchema.Pattern != "" || keySchema.Enum != nil {
+			mapSchema.PropertyNames = keySchema
+		}
+
+		return mapSchema
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return integerSchema(t)
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+
+	// interface{} & everything else accepts any value
+	return &Schema{}
+}
+
+// Struct returns the inline object schema of the struct.
+func (g *Generator) Struct(t reflect.Type) *Schema {
+	object := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		Required:             g.Required[t],
+		AdditionalProperties: false,
+	}
+
+	g.addFields(object, t)
+
+	return object
+}
+
+// addFields adds the tagged fields of the struct (and its squashed embedded structs) to the object.
+func (g *Generator) addFields(object *Schema, t reflect.Type) {
+	for idx := range t.NumField() {
+		field := t.Field(idx)
+
+		tag, ok := field.Tag.Lookup("mapstructure")
+		if !ok || tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if strings.Contains(options, "squash") {
+			g.addFields(object, field.Type)
+
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		if fieldSchema, ok := g.Fields[t.String()+"."+field.Name]; ok {
+			object.Properties[name] = fieldSchema
+
+			continue
+		}
+
+		object.Properties[name] = g.Reflect(field.Type)
+	}
+}
+
+// integerSchema returns the schema of an integer type with its value range (ranges beyond 32 bits are omitted).
+func integerSchema(t reflect.Type) *Schema {
+	integer := &Schema{Type: "integer"}
+	bits := float64(t.Bits())
+
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum, maximum := 0.0, math.Pow(2, bits)-1
+
+		integer.Minimum = &minimum
+		if bits <= 32 {
+			integer.Maximum = &maximum
+		}
+
+	default:
+		if bits <= 32 {
+			minimum, maximum := -math.Pow(2, bits-1), math.Pow(2, bits-1)-1
+			integer.Minimum, integer.Maximum = &minimum, &maximum
+		}
+	}
+
+	return integer
+}
@@ -0,0 +1,63 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// Ready tells systemd that the service is up (Type=notify).
+	Ready = "READY=1"
+	// Watchdog keeps the systemd watchdog (WatchdogSec) from restarting the service.
+	Watchdog = "WATCHDOG=1"
+	// Stopping tells systemd that the service is shutting down.
+	Stopping = "STOPPING=1"
+)
+
+// Notify sends the state to the systemd notify socket (sd_notify).
+// returns false without an error if not running as a systemd notify service.
+func Notify(state string) (bool, error) {
+	socketPath := os.Getenv("NOTIFY_SOCKET")
+	if socketPath == "" {
+		return false, nil
+	}
+
+	// abstract namespace socket
+	if socketPath[0] == '@' {
+		socketPath = "\x00" + socketPath[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Status returns the notify message to set the status text shown by systemctl status.
+func Status(status string) string {
+	return "STATUS=" + status
+}
+
+// WatchdogInterval returns the watchdog timeout configured by WatchdogSec (0 if disabled).
+func WatchdogInterval() time.Duration {
+	watchdogUSec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || watchdogUSec <= 0 {
+		return 0
+	}
+
+	// the watchdog is meant for another process
+	if watchdogPID := os.Getenv("WATCHDOG_PID"); watchdogPID != "" && watchdogPID != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+
+	return time.Duration(watchdogUSec) * time.Microsecond
+}
@@ -1,11 +1,15 @@
+//go:generate sh -c "go run . schema > automoli.schema.json"
+
 package main
 
 import (
 	"os"
 	"os/signal"
+	"syscall"
 
 	"github.com/benleb/automoli-go/cmd"
 	"github.com/benleb/automoli-go/internal/automoli"
+	"github.com/benleb/automoli-go/internal/systemd"
 	"github.com/charmbracelet/log"
 )
 
@@ -22,14 +26,19 @@ func main() {
 
 	// signal handler channel
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
 		sig := <-c
 
-		// ctrl+c handler
+		// ctrl+c & systemctl stop handler
 		log.Debugf("Got %s signal. aborting...\n", sig)
 
+		// tell systemd we are shutting down (does nothing if not running as systemd notify service)
+		if _, err := systemd.Notify(systemd.Stopping); err != nil {
+			log.With("err", err).Warn("notifying systemd failed")
+		}
+
 		os.Exit(0)
 	}()
 